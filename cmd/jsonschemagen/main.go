@@ -79,12 +79,15 @@ func (f *Flags) PipeOut() (io.WriteCloser, error) {
 
 func main() {
 	cmd := cobra.Command{
-		Use:   "jsonschemagen [flags] [schema file]...",
+		Use:   "jsonschemagen [flags] [schema file | directory | glob]...",
 		Short: "Generate Go types and helpers for the specified JSON schema.",
 		Long: `Generate Go types and helpers for the specified JSON schema.
 If no schema file is specified or specified to "-", read from stdin.
+Directories are walked recursively, following symbolic links,
+and glob patterns support "**" to match any number of directories.
 `,
-		Example: "$ find schema -name '*.json' | xargs jsonschemagen --rootdir=$PWD -n out > out/generated.go",
+		Example: `$ jsonschemagen --rootdir=$PWD -n out schema > out/generated.go
+$ jsonschemagen --rootdir=$PWD -n out 'schema/**/*.json' --exclude '**/testdata/**' > out/generated.go`,
 	}
 	flags := Flags{}
 	cmd.Flags().StringVarP(&flags.SchemaFilename, "schema", "s", "", `The schema filename, deprecated.
@@ -97,9 +100,41 @@ If not provided or specified to "-", output to stdout.`)
 	loaderOpts := loader.ParseOptions{}
 	cmd.Flags().StringVar(&loaderOpts.BaseURI, "baseuri", "", "base URI")
 	cmd.Flags().StringVar(&loaderOpts.RootDir, "rootdir", "", "root directory")
+	cmd.Flags().StringVar(&loaderOpts.StdinURI, "stdin-uri", "", `The URI of the schema read from stdin, used when it has no $id.
+A path without scheme is mapped like a schema file, e.g. "schema/stdin.json".
+When stdin contains several JSON documents, the index is appended to the file name,
+or to an opaque URI. Without stdin URI, the documents after the first are "stdin_1.json", "stdin_2.json"...`)
+	cmd.Flags().IntVarP(&loaderOpts.Jobs, "jobs", "j", 0, `The maximum number of schema files loaded in parallel.
+If not provided, use the number of CPUs.`)
+	inputFormat := ""
+	cmd.Flags().StringVar(&inputFormat, "input-format", "", `The syntax of the schema files, one of "json", "jsonc" or "json5".
+"jsonc" allows comments and trailing commas.
+If not provided, ".jsonc" and ".json5" files are relaxed, others are strict JSON.`)
+	cmd.Flags().StringSliceVar(&loaderOpts.Include, "include", nil, `Only load files matching the patterns when walking directories.
+The files matching a glob are all loaded, unless excluded.
+Patterns without "/" match the file name. (default "*.json", "*.jsonc", "*.json5")`)
+	cmd.Flags().StringSliceVar(&loaderOpts.Exclude, "exclude", nil, `Skip files matching the patterns when walking directories or globs.
+Patterns without "/" match the file name.`)
+	cmd.Flags().StringSliceVar(&loaderOpts.Override, "override", nil, `When files declare the same $id with different content, use the schema of the file
+matching the first pattern. Identical copies are always deduplicated.
+Patterns without "/" match the file name, others the path relative to --rootdir,
+or to the working directory without --rootdir.`)
 
 	generatorOpts := generator.GeneratorOptions{}
 	cmd.Flags().BoolVar(&generatorOpts.WithAdditionalProperties, "with-additional-properties", false, "Generate additional properties and pattern properties")
+	cmd.Flags().BoolVar(&generatorOpts.StrictRefs, "strict-refs", false, `Fail on unresolved references, listing all of them.
+By default, unresolved references are generated as json.RawMessage.`)
+	cmd.Flags().BoolVar(&generatorOpts.CollapseRefChains, "collapse-refs", false, `Use the final type of a chain of references, e.g. a $defs only made of a $ref.
+By default, the intermediate schemas are generated as type aliases.`)
+	cmd.Flags().BoolVar(&generatorOpts.FormatsPackage, "formats-package", false, `Map the byte format and base64 strings to []byte, and the date, uri, uuid and duration
+formats to the types of github.com/RyoJerryYu/go-jsonschema/formats, imported by the generated code.
+By default they are strings.`)
+	cmd.Flags().StringToStringVar(&generatorOpts.FormatTypes, "format-type", nil, `Map a string format to a Go type qualified by its import path,
+e.g. "uuid=github.com/google/uuid.UUID", or to "" to keep it a string.
+By default date-time is a time.Time, and the other formats are strings.`)
+	cmd.Flags().StringToStringVar(&generatorOpts.TypeOverrides, "type-override", nil, `Map the $id of a schema, or its URI with a JSON pointer fragment, to an existing Go type
+qualified by its import path, e.g. "https://corp/schemas/money.json=github.com/corp/lib/money.Amount".
+The schema is not generated, and the references to it use the existing type.`)
 	cmd.Flags().StringSliceVarP(&generatorOpts.UpperPropertyNames, "upper-property-names", "u", nil, `Apply full upper case to the property names.
 e.g. given "id", "Id" or "ID" as flags, when a type or field name 
 parsed as "Id", would be converted as "ID"`)
@@ -108,12 +143,19 @@ parsed as "Id", would be converted as "ID"`)
 		err := flags.Format()
 		checkError(err)
 
+		loaderOpts.Format, err = loader.ParseFormat(inputFormat)
+		checkError(err)
+
 		filePaths := args[:]
 		if flags.SchemaFilename != "" {
 			filePaths = append(filePaths, flags.SchemaFilename)
 		}
 
-		schemas, err := loader.New(&loaderOpts).LoadAll(filePaths)
+		l := loader.New(&loaderOpts)
+		filePaths, err = l.ExpandPaths(filePaths)
+		checkError(err)
+
+		schemas, err := l.LoadAll(filePaths)
 		checkError(err)
 		f := jen.NewFile(flags.PkgName)
 
@@ -138,7 +180,7 @@ parsed as "Id", would be converted as "ID"`)
 func checkError(err error) {
 	if err != nil {
 		if e, ok := err.(*errors.Error); ok {
-			fmt.Fprintln(os.Stderr, string(e.Stack()))
+			fmt.Fprintln(os.Stderr, e.ErrorStack())
 		} else {
 			log.Fatalf("error: %v", err)
 		}
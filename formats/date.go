@@ -0,0 +1,59 @@
+package formats
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date is a calendar date without time nor location, encoded as a full-date
+// of RFC 3339, e.g. "2006-01-02".
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+const dateLayout = "2006-01-02"
+
+// DateOf returns the date of the time, in its location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// ParseDate parses a full-date of RFC 3339.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	return DateOf(t), nil
+}
+
+// String returns the date as a full-date of RFC 3339.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns the time at the start of the date in the location.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports whether the date is the zero value.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(b []byte) error {
+	date, err := ParseDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
@@ -0,0 +1,4 @@
+// Package formats provides the Go types of the string formats without
+// a type of the standard library encoded as a JSON string, used by the
+// generated code, e.g. Date for "format": "date".
+package formats
@@ -0,0 +1,149 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a duration of ISO 8601, e.g. "P1Y6M", "PT1H30M" or "P1DT12H".
+// Years and months have no fixed duration: they are kept in Years and Months,
+// to be added to a time with AddTo. The rest is the embedded time.Duration,
+// a day being 24 hours and a week 7 days.
+type Duration struct {
+	Years  int
+	Months int
+	time.Duration
+}
+
+// ParseDuration parses a duration of ISO 8601.
+// Years and months must be whole numbers.
+func ParseDuration(s string) (Duration, error) {
+	invalid := func(reason string) (Duration, error) {
+		return Duration{}, fmt.Errorf("invalid duration %q: %s", s, reason)
+	}
+
+	rest := s
+	negative := strings.HasPrefix(rest, "-")
+	rest = strings.TrimPrefix(rest, "-")
+	if !strings.HasPrefix(rest, "P") || rest == "P" || strings.HasSuffix(rest, "T") {
+		return invalid("not in the form PnYnMnDTnHnMnS")
+	}
+	rest = rest[1:]
+
+	var duration Duration
+	var d float64
+	inTime := false
+	units := "YMWD"
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return invalid("duplicate T")
+			}
+			inTime, units = true, "HMS"
+			rest = rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if i <= 0 {
+			return invalid("missing number")
+		}
+		n, err := strconv.ParseFloat(strings.Replace(rest[:i], ",", ".", 1), 64)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		unit := rest[i]
+		j := strings.IndexByte(units, unit)
+		if j < 0 {
+			return invalid("unexpected " + string(unit))
+		}
+		units = units[j+1:]
+		if !inTime && (unit == 'Y' || unit == 'M') {
+			if n != float64(int(n)) {
+				return invalid("years and months must be whole numbers")
+			}
+			if unit == 'Y' {
+				duration.Years = int(n)
+			} else {
+				duration.Months = int(n)
+			}
+			rest = rest[i+1:]
+			continue
+		}
+		switch unit {
+		case 'W':
+			d += n * float64(7*24*time.Hour)
+		case 'D':
+			d += n * float64(24*time.Hour)
+		case 'H':
+			d += n * float64(time.Hour)
+		case 'M':
+			d += n * float64(time.Minute)
+		case 'S':
+			d += n * float64(time.Second)
+		}
+		rest = rest[i+1:]
+	}
+	duration.Duration = time.Duration(d)
+	if negative {
+		duration.Years, duration.Months, duration.Duration = -duration.Years, -duration.Months, -duration.Duration
+	}
+	return duration, nil
+}
+
+// AddTo returns t plus the duration, the years and months being added
+// as calendar years and months.
+func (d Duration) AddTo(t time.Time) time.Time {
+	return t.AddDate(d.Years, d.Months, 0).Add(d.Duration)
+}
+
+// String returns the duration in ISO 8601, in years, months, hours,
+// minutes and seconds, e.g. "P1Y6M" or "PT36H0.5S".
+func (d Duration) String() string {
+	if d.Years == 0 && d.Months == 0 && d.Duration == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	years, months, v := d.Years, d.Months, d.Duration
+	if years < 0 || months < 0 || v < 0 {
+		b.WriteString("-")
+		years, months, v = -years, -months, -v
+	}
+	b.WriteString("P")
+	if years > 0 {
+		b.WriteString(strconv.Itoa(years) + "Y")
+	}
+	if months > 0 {
+		b.WriteString(strconv.Itoa(months) + "M")
+	}
+	if v == 0 {
+		return b.String()
+	}
+	b.WriteString("T")
+	if h := v / time.Hour; h > 0 {
+		b.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		v -= h * time.Hour
+	}
+	if m := v / time.Minute; m > 0 {
+		b.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		v -= m * time.Minute
+	}
+	if v > 0 {
+		b.WriteString(strconv.FormatFloat(v.Seconds(), 'f', -1, 64) + "S")
+	}
+	return b.String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	duration, err := ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = duration
+	return nil
+}
@@ -0,0 +1,131 @@
+package formats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	type value struct {
+		Date     Date     `json:"date"`
+		UUID     UUID     `json:"uuid"`
+		URL      URL      `json:"url"`
+		Duration Duration `json:"duration"`
+	}
+	data := `{"date":"2024-02-29","uuid":"f81d4fae-7dec-11d0-a765-00a0c91e6bf6","url":"https://example.com/a?b=c","duration":"PT36H0.5S"}`
+
+	var v value
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Date != (Date{2024, time.February, 29}) {
+		t.Errorf("unexpected date %v", v.Date)
+	}
+	if v.UUID[0] != 0xf8 || v.UUID[15] != 0xf6 {
+		t.Errorf("unexpected uuid %v", v.UUID)
+	}
+	if v.URL.Host != "example.com" || v.URL.Query().Get("b") != "c" {
+		t.Errorf("unexpected url %v", v.URL)
+	}
+	if v.Duration.Duration != 36*time.Hour+500*time.Millisecond {
+		t.Errorf("unexpected duration %v", v.Duration.Duration)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != data {
+		t.Errorf("expected %s, got %s", data, out)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"PT0S":      0,
+		"PT1H30M":   90 * time.Minute,
+		"P1DT12H":   36 * time.Hour,
+		"P2W":       14 * 24 * time.Hour,
+		"PT0,5S":    500 * time.Millisecond,
+		"-PT10M":    -10 * time.Minute,
+		"P1DT1M1S":  24*time.Hour + time.Minute + time.Second,
+		"PT1.5H":    90 * time.Minute,
+		"P0D":       0,
+		"PT100000S": 100000 * time.Second,
+	} {
+		d, err := ParseDuration(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if d.Duration != expected {
+			t.Errorf("%s: expected %v, got %v", s, expected, d.Duration)
+		}
+	}
+
+	for s, expected := range map[string]string{
+		"":       "not in the form",
+		"P":      "not in the form",
+		"PT":     "not in the form",
+		"1H":     "not in the form",
+		"P0.5Y":  "whole numbers",
+		"P1M1Y":  "unexpected Y",
+		"PT1D":   "unexpected D",
+		"P1H":    "unexpected H",
+		"PT1S1M": "unexpected M",
+		"PTH":    "missing number",
+	} {
+		_, err := ParseDuration(s)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected %q, got %v", s, expected, err)
+		}
+	}
+}
+
+func TestCalendarDuration(t *testing.T) {
+	for s, expected := range map[string]Duration{
+		"P1Y":        {Years: 1},
+		"P2M":        {Months: 2},
+		"P1Y6MT12H":  {Years: 1, Months: 6, Duration: 12 * time.Hour},
+		"-P1YT1M":    {Years: -1, Duration: -time.Minute},
+		"P1Y2M3DT4H": {Years: 1, Months: 2, Duration: 76 * time.Hour},
+	} {
+		d, err := ParseDuration(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if d != expected {
+			t.Errorf("%s: expected %+v, got %+v", s, expected, d)
+		}
+	}
+
+	d := Duration{Years: 1, Months: 1, Duration: 36 * time.Hour}
+	if s := d.String(); s != "P1Y1MT36H" {
+		t.Errorf("expected P1Y1MT36H, got %s", s)
+	}
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	if end := d.AddTo(start); !end.Equal(time.Date(2025, time.March, 4, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected end %v", end)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		target interface{}
+	}{
+		{`"2024-02-30"`, &Date{}},
+		{`"2024-2-1"`, &Date{}},
+		{`"f81d4fae7dec11d0a76500a0c91e6bf6"`, &UUID{}},
+		{`"f81d4fae-7dec-11d0-a765-00a0c91e6bfz"`, &UUID{}},
+		{`"/relative/path"`, &URL{}},
+		{`"P1H"`, &Duration{}},
+	} {
+		if err := json.Unmarshal([]byte(c.data), c.target); err == nil {
+			t.Errorf("%s: expected an error", c.data)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package formats
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// URL is a url.URL encoded as a string.
+type URL struct {
+	url.URL
+}
+
+// ParseURL parses an absolute URI of RFC 3986.
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+	if !u.IsAbs() {
+		return URL{}, fmt.Errorf("invalid uri %q: not absolute", s)
+	}
+	return URL{URL: *u}, nil
+}
+
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *URL) UnmarshalText(b []byte) error {
+	parsed, err := ParseURL(string(b))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
@@ -0,0 +1,51 @@
+package formats
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// UUID is a UUID of RFC 4122, encoded in its canonical form,
+// e.g. "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+type UUID [16]byte
+
+// ParseUUID parses a UUID in its canonical form, case insensitively.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid uuid %q", s)
+	}
+	src := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(u[:], []byte(src)); err != nil {
+		return UUID{}, fmt.Errorf("invalid uuid %q: %w", s, err)
+	}
+	return u, nil
+}
+
+// String returns the canonical form of the UUID, in lower case.
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(b []byte) error {
+	uuid, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = uuid
+	return nil
+}
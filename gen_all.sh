@@ -24,7 +24,7 @@ function gen_one() {
 
     local OUT_FILE=$OUT_DIR/$NAME.schema.go
     
-    jsonschemagen -s $FILE -o $OUT_FILE
+    jsonschemagen -o $OUT_FILE $FILE
 }
 
 for FILE in $(find -L $SCHEMA_DIR -name '*.json'); do
@@ -0,0 +1,324 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+	"github.com/go-errors/errors"
+)
+
+// mergedAllOf is the members of an allOf merged into a single type.
+type mergedAllOf struct {
+	// schema is the schema with the properties, required lists and
+	// additional properties of its inline members
+	schema *jsonschema.Schema
+	// embedded is the members referring to an object schema,
+	// generated as embedded structs
+	embedded []*jsonschema.Schema
+	// alias is the single member referring to a schema of another type,
+	// which is the type of the allOf
+	alias *jsonschema.Schema
+}
+
+// mergeAllOf merges the allOf members of the schema, and the schema itself,
+// with the properties of its conditional subschemas as optional properties.
+// Returns an error when two members define a property with different types.
+func (g *Generator) mergeAllOf(schema *jsonschema.Schema) (*mergedAllOf, error) {
+	if g.merging[schema] {
+		return nil, fmt.Errorf("cyclic allOf in %s", g.resolver.uriOf(schema))
+	}
+	g.merging[schema] = true
+	defer delete(g.merging, schema)
+
+	merged := *schema
+	merged.Ref = ""
+	merged.AllOf = nil
+	merged.Properties = make(map[string]*jsonschema.Schema)
+	merged.Required = append([]string{}, schema.Required...)
+	result := &mergedAllOf{schema: &merged}
+
+	// all the properties, including the ones of the embedded structs,
+	// with where they are defined, to report conflicts
+	props := map[string]*jsonschema.Schema{}
+	sources := map[string]string{}
+	addProperty := func(name string, prop *jsonschema.Schema, source string, embedded bool) error {
+		existing, ok := props[name]
+		if ok {
+			existingKey, key := g.typeKey(existing), g.typeKey(prop)
+			switch {
+			case key == "" || existingKey == key:
+				// only constraints or annotations, or the same type
+				return nil
+			case existingKey != "":
+				return fmt.Errorf("conflicting types for property %q: %s in %s, %s in %s", name, existingKey, sources[name], key, source)
+			}
+		}
+		props[name], sources[name] = prop, source
+		if embedded {
+			delete(merged.Properties, name)
+		} else {
+			merged.Properties[name] = prop
+		}
+		return nil
+	}
+
+	for _, name := range sortedKeys(schema.Properties) {
+		if err := addProperty(name, schema.Properties[name], g.resolver.uriOf(schema), false); err != nil {
+			return nil, err
+		}
+	}
+
+	// embeddedIn is the embedded schema defining each property
+	embeddedIn := map[string]*jsonschema.Schema{}
+	var embed func(member *jsonschema.Schema) error
+	// mergeRef merges the properties of a referred schema rather than
+	// embedding it, and embeds its own embedded members
+	mergeRef := func(target *jsonschema.Schema) error {
+		sub, err := g.mergeAllOf(target)
+		if err != nil {
+			return err
+		}
+		if err := g.mergeMember(&merged, sub.schema, addProperty); err != nil {
+			return err
+		}
+		for _, embedded := range sub.embedded {
+			if err := embed(embedded); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	embed = func(member *jsonschema.Schema) error {
+		chain, err := g.resolver.GetRefChain(member)
+		if err != nil {
+			return err
+		}
+		target := chain[len(chain)-1]
+		for name := range target.Properties {
+			if _, ok := embeddedIn[name]; ok {
+				// promoted from two embedded structs at the same depth,
+				// the field would be ignored by encoding/json as ambiguous
+				return mergeRef(target)
+			}
+		}
+		result.embedded = append(result.embedded, member)
+		for _, name := range sortedKeys(target.Properties) {
+			if err := addProperty(name, target.Properties[name], g.resolver.uriOf(target), true); err != nil {
+				return err
+			}
+			embeddedIn[name] = target
+		}
+		return nil
+	}
+
+	var members []*jsonschema.Schema
+	if schema.Ref != "" {
+		// the properties beside a $ref extend the referenced schema,
+		// which is the first member, referred by its absolute URI
+		_, resolved, err := g.resolver.resolve(schema, schema.Ref)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, &jsonschema.Schema{Ref: resolved.String()})
+	}
+	for i := range schema.AllOf {
+		members = append(members, &schema.AllOf[i])
+	}
+
+	var others []*jsonschema.Schema
+	for _, member := range members {
+		if member.Ref == "" {
+			if err := g.mergeMember(&merged, member, addProperty); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if _, _, ok := g.refOverride(member); ok {
+			// an existing type, the properties of which are unknown,
+			// and the JSON methods of which would be promoted if embedded
+			others = append(others, member)
+			continue
+		}
+		chain, err := g.resolver.GetRefChain(member)
+		if err != nil {
+			return nil, err
+		}
+		target := chain[len(chain)-1]
+		if g.isConditional(target) {
+			// merged rather than embedded, for its conditions to be checked
+			// by the UnmarshalJSON of the merged struct
+			if err := mergeRef(target); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if target.SchemaType() != jsonschema.TypeObject && !g.isMergedObject(target) || isUnion(target) {
+			others = append(others, member)
+			continue
+		}
+		if err := embed(member); err != nil {
+			return nil, err
+		}
+	}
+
+	// the properties of the conditional subschemas are optional fields
+	for _, conditional := range g.conditionalSchemas(schema) {
+		for _, branch := range g.conditionalBranches(conditional) {
+			for _, name := range sortedKeys(branch.Properties) {
+				if err := addProperty(name, branch.Properties[name], g.resolver.uriOf(branch), false); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	switch {
+	case len(others) == 0:
+	case len(others) == 1 && len(result.embedded) == 0 && len(merged.Properties) == 0:
+		result.alias = others[0]
+	default:
+		for _, other := range others {
+			if spec, _, ok := g.refOverride(other); ok {
+				_, resolved, _ := g.resolver.resolve(other, other.Ref)
+				return nil, fmt.Errorf("can not extend %s, mapped to the Go type %s", resolved, spec)
+			}
+		}
+		return nil, fmt.Errorf("can not merge %s with objects", others[0].Ref)
+	}
+	if len(result.embedded) > 0 || len(props) > 0 || len(g.conditionalSchemas(schema)) > 0 {
+		merged.Type = jsonschema.TypeSet{jsonschema.TypeObject}
+	}
+	return result, nil
+}
+
+// mergeMember merges an inline allOf member into merged.
+func (g *Generator) mergeMember(merged, member *jsonschema.Schema, addProperty func(string, *jsonschema.Schema, string, bool) error) error {
+	for _, name := range sortedKeys(member.Properties) {
+		if err := addProperty(name, member.Properties[name], g.resolver.uriOf(member), false); err != nil {
+			return err
+		}
+	}
+	merged.Required = append(merged.Required, member.Required...)
+	if len(merged.Type) == 0 {
+		merged.Type = member.Type
+	}
+	// the most restrictive additional properties
+	switch {
+	case member.AdditionalProperties == nil:
+	case merged.AdditionalProperties == nil, member.AdditionalProperties.IsFalse():
+		merged.AdditionalProperties = member.AdditionalProperties
+	}
+	for pattern, prop := range member.PatternProperties {
+		if merged.PatternProperties == nil {
+			merged.PatternProperties = make(map[string]*jsonschema.Schema)
+		}
+		if _, ok := merged.PatternProperties[pattern]; !ok {
+			merged.PatternProperties[pattern] = prop
+		}
+	}
+	return nil
+}
+
+// isMergedObject reports whether the allOf or the conditions of the schema
+// are generated as a struct.
+func (g *Generator) isMergedObject(schema *jsonschema.Schema) bool {
+	if len(schema.AllOf) == 0 && !hasConditions(schema) && (schema.Ref == "" || len(schema.Properties) == 0) {
+		return false
+	}
+	merged, err := g.mergeAllOf(schema)
+	return err == nil && merged.alias == nil && merged.schema.SchemaType() == jsonschema.TypeObject
+}
+
+// typeKey identifies the Go type a property is generated as,
+// empty for a schema only adding constraints or annotations.
+func (g *Generator) typeKey(schema *jsonschema.Schema) string {
+	if schema.Ref != "" {
+		chain, err := g.resolver.GetRefChain(schema)
+		if err != nil {
+			return schema.Ref
+		}
+		return g.resolver.uriOf(chain[len(chain)-1])
+	}
+	t := string(schema.SchemaType())
+	switch {
+	case isEnum(schema):
+		return "enum " + g.resolver.uriOf(schema)
+	case isUnion(schema):
+		return "union " + g.resolver.uriOf(schema)
+	case t == string(jsonschema.TypeArray) && schema.Items != nil:
+		if items := g.typeKey(schema.Items); items != "" {
+			return "array of " + items
+		}
+	}
+	return t
+}
+
+// CheckAllOf merges the allOf and the conditional properties of all the
+// schemas and their subschemas, and returns an error listing all the
+// conflicting properties.
+func (g *Generator) CheckAllOf() error {
+	var msgs []string
+	var check func(schema *jsonschema.Schema)
+	check = func(schema *jsonschema.Schema) {
+		if (len(schema.AllOf) > 0 || hasConditions(schema)) && schema.Ref == "" || g.extendsRef(schema) {
+			if _, err := g.mergeAllOf(schema); err != nil {
+				keyword := "allOf"
+				switch {
+				case schema.Ref != "":
+					keyword = "properties beside $ref"
+				case len(schema.AllOf) == 0:
+					keyword = "conditional properties"
+				}
+				var unresolved *UnresolvedRefError
+				if !errors.As(err, &unresolved) {
+					msgs = append(msgs, g.sourceOf(schema)+": "+keyword+": "+err.Error())
+				}
+			}
+		}
+		schema.Subschemas(func(_ []string, subschema *jsonschema.Schema) {
+			check(subschema)
+		})
+	}
+	for _, schema := range g.schemas {
+		check(schema)
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.Errorf("%d invalid allOf:\n\t%s", len(msgs), strings.Join(msgs, "\n\t"))
+}
+
+// sourceOf describes where the schema is defined, for error messages.
+func (g *Generator) sourceOf(schema *jsonschema.Schema) string {
+	return g.resolver.sourceOf(schema, schema.ID)
+}
+
+// generateAllOf generates the allOf of the schema as a struct embedding the
+// referred objects, with the properties of the inline members.
+// Falls back to json.RawMessage when the members can not be merged.
+func (g *Generator) generateAllOf(schema *jsonschema.Schema, required bool) jen.Code {
+	merged, err := g.mergeAllOf(schema)
+	if err != nil {
+		return jen.Qual("encoding/json", "RawMessage")
+	}
+	if merged.alias != nil {
+		return g.generateSchemaType(merged.alias, required)
+	}
+	if merged.schema.SchemaType() != jsonschema.TypeObject {
+		return g.generateSchemaType(merged.schema, required)
+	}
+
+	embedded := make([]jen.Code, len(merged.embedded))
+	for i, member := range merged.embedded {
+		embedded[i] = g.generateSchemaType(member, true)
+	}
+	var t jen.Code = g.generateStruct(merged.schema, embedded...)
+	if !required {
+		t = jen.Op("*").Add(t)
+	}
+	return t
+}
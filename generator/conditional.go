@@ -0,0 +1,282 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+)
+
+// hasConditions reports whether the schema requires properties under
+// conditions, by if/then/else, dependentSchemas or dependentRequired.
+func hasConditions(schema *jsonschema.Schema) bool {
+	return schema.If != nil && (schema.Then != nil || schema.Else != nil) ||
+		len(schema.DependentSchemas) > 0 || len(schema.DependentRequired) > 0
+}
+
+// conditionalSchemas returns the schema and its allOf members merged into
+// it which have conditions, following the references of the members,
+// and the $ref extended by the properties of the schema.
+func (g *Generator) conditionalSchemas(schema *jsonschema.Schema) []*jsonschema.Schema {
+	return g.collectConditionalSchemas(schema, map[*jsonschema.Schema]bool{})
+}
+
+func (g *Generator) collectConditionalSchemas(schema *jsonschema.Schema, seen map[*jsonschema.Schema]bool) []*jsonschema.Schema {
+	if seen[schema] {
+		return nil
+	}
+	seen[schema] = true
+
+	var schemas []*jsonschema.Schema
+	if hasConditions(schema) {
+		schemas = append(schemas, schema)
+	}
+	members := make([]*jsonschema.Schema, 0, len(schema.AllOf)+1)
+	if schema.Ref != "" && len(schema.Properties) > 0 {
+		members = append(members, schema)
+	}
+	for i := range schema.AllOf {
+		members = append(members, &schema.AllOf[i])
+	}
+	for _, member := range members {
+		if member.Ref != "" {
+			chain, err := g.resolver.GetRefChain(member)
+			if err != nil {
+				continue
+			}
+			member = chain[len(chain)-1]
+		}
+		schemas = append(schemas, g.collectConditionalSchemas(member, seen)...)
+	}
+	return schemas
+}
+
+// isConditional reports whether the schema is generated as a named struct
+// checking its conditional requirements when decoded.
+func (g *Generator) isConditional(schema *jsonschema.Schema) bool {
+	return len(g.conditionalSchemas(schema)) > 0 && (schema.Ref == "" || g.extendsRef(schema))
+}
+
+// conditionalBranches returns the subschemas applying under a condition,
+// the properties of which are optional fields of the parent.
+func (g *Generator) conditionalBranches(schema *jsonschema.Schema) []*jsonschema.Schema {
+	var branches []*jsonschema.Schema
+	if schema.If != nil {
+		for _, branch := range []*jsonschema.Schema{schema.Then, schema.Else} {
+			if branch != nil {
+				branches = append(branches, branch)
+			}
+		}
+	}
+	for _, name := range sortedKeys(schema.DependentSchemas) {
+		branches = append(branches, schema.DependentSchemas[name])
+	}
+
+	for i, branch := range branches {
+		if branch.Ref == "" {
+			continue
+		}
+		if chain, err := g.resolver.GetRefChain(branch); err == nil {
+			branches[i] = chain[len(chain)-1]
+		}
+	}
+	return branches
+}
+
+// requiredBy returns the properties required by a conditional subschema.
+func (g *Generator) requiredBy(branch *jsonschema.Schema) []string {
+	if branch == nil {
+		return nil
+	}
+	if branch.Ref != "" {
+		chain, err := g.resolver.GetRefChain(branch)
+		if err != nil {
+			return nil
+		}
+		branch = chain[len(chain)-1]
+	}
+	return branch.Required
+}
+
+// ifCondition returns the statements setting matches to the result of the if
+// schema on the props of the value, with the description of the condition.
+// Only the properties with a const or an enum of scalars, and required,
+// can be checked; ok is false for other conditions.
+func ifCondition(schema *jsonschema.Schema) (code []jen.Code, description string, ok bool) {
+	rest := *schema
+	rest.Properties, rest.Required, rest.Type = nil, nil, nil
+	if rest.Ref != "" || rest.HasSiblingKeywords() {
+		return nil, "", false
+	}
+	if len(schema.Type) > 0 && schema.SchemaType() != jsonschema.TypeObject {
+		return nil, "", false
+	}
+
+	var descriptions []string
+	code = append(code, jen.Id("matches").Op("=").True())
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[name]
+		members := enumMembers(prop)
+		kind, nullable := kindOfEnum(prop)
+		if len(members) == 0 || kind == enumMixed || nullable || len(prop.OneOf) > 0 {
+			return nil, "", false
+		}
+
+		equals := jen.Null()
+		var texts []string
+		for i, member := range members {
+			if i > 0 {
+				equals.Op("||")
+			}
+			equals.Id("value").Op("==").Add(enumLit(member.value, kind))
+			texts = append(texts, string(enumRawValue(member.value)))
+		}
+		if len(members) > 1 {
+			equals = jen.Parens(equals)
+		}
+		t := enumBaseType(kind)
+		if kind == enumInteger {
+			// 1.0 is an integer too
+			t = jen.Float64()
+		}
+		// an absent property matches
+		code = append(code, jen.If(
+			jen.List(jen.Id("raw"), jen.Id("ok")).Op(":=").Id("props").Index(jen.Lit(name)),
+			jen.Id("ok"),
+		).Block(
+			jen.Var().Id("value").Add(t),
+			jen.Id("matches").Op("=").Id("matches").Op("&&").
+				Qual("encoding/json", "Unmarshal").Call(jen.Id("raw"), jen.Op("&").Id("value")).Op("==").Nil().
+				Op("&&").Add(equals),
+		))
+		if len(texts) == 1 {
+			descriptions = append(descriptions, name+" is "+texts[0])
+		} else {
+			descriptions = append(descriptions, name+" is one of "+strings.Join(texts, ", "))
+		}
+	}
+	for _, name := range schema.Required {
+		code = append(code, jen.If(
+			jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Id("props").Index(jen.Lit(name)),
+			jen.Op("!").Id("ok"),
+		).Block(
+			jen.Id("matches").Op("=").False(),
+		))
+		descriptions = append(descriptions, name+" is set")
+	}
+	if len(descriptions) == 0 {
+		return nil, "", false
+	}
+	return code, strings.Join(descriptions, " and "), true
+}
+
+// require returns the statement checking that the properties are set,
+// with the description of the condition requiring them.
+func require(when string, names []string) jen.Code {
+	args := []jen.Code{jen.Lit(when)}
+	for _, name := range names {
+		args = append(args, jen.Lit(name))
+	}
+	return jen.If(
+		jen.Err().Op(":=").Id("require").Call(args...),
+		jen.Err().Op("!=").Nil(),
+	).Block(jen.Return(jen.Err()))
+}
+
+// generateConditions generates the UnmarshalJSON method of the struct,
+// checking the properties required by the conditions of the schema, e.g.
+//
+//	if: { properties: { type: { const: "card" } } }
+//	then: { required: [cardNumber] }
+//
+// fails to decode a value of type "card" without a cardNumber.
+// Conditions on other keywords than const, enum and required are not checked.
+func (g *Generator) generateConditions(schema *jsonschema.Schema, id string, file *jen.File) {
+	var checks []jen.Code
+	var matches bool
+	for _, conditional := range g.conditionalSchemas(schema) {
+		if conditional.If != nil {
+			then, otherwise := g.requiredBy(conditional.Then), g.requiredBy(conditional.Else)
+			code, description, ok := ifCondition(conditional.If)
+			switch {
+			case len(then) == 0 && len(otherwise) == 0:
+			case !ok:
+				checks = append(checks, jen.Comment("the if condition of "+g.resolver.uriOf(conditional)+" is not checked"))
+			default:
+				matches = true
+				checks = append(checks, jen.Comment("if "+description))
+				checks = append(checks, code...)
+				switch {
+				case len(otherwise) == 0:
+					checks = append(checks, jen.If(jen.Id("matches")).Block(require("when "+description, then)))
+				case len(then) == 0:
+					checks = append(checks, jen.If(jen.Op("!").Id("matches")).Block(require("unless "+description, otherwise)))
+				default:
+					checks = append(checks, jen.If(jen.Id("matches")).Block(
+						require("when "+description, then),
+					).Else().Block(
+						require("unless "+description, otherwise),
+					))
+				}
+			}
+		}
+
+		var names []string
+		for name := range conditional.DependentRequired {
+			names = append(names, name)
+		}
+		for name := range conditional.DependentSchemas {
+			if _, ok := conditional.DependentRequired[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			required := append(append([]string{}, conditional.DependentRequired[name]...), g.requiredBy(conditional.DependentSchemas[name])...)
+			if len(required) == 0 {
+				continue
+			}
+			checks = append(checks, jen.If(
+				jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Id("props").Index(jen.Lit(name)),
+				jen.Id("ok"),
+			).Block(require("when "+name+" is set", required)))
+		}
+	}
+	if len(checks) == 0 {
+		return
+	}
+
+	body := []jen.Code{
+		jen.Type().Id("plain").Id(id),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Parens(jen.Op("*").Id("plain")).Parens(jen.Id("v"))),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())),
+		jen.Var().Id("props").Map(jen.String()).Qual("encoding/json", "RawMessage"),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("props")),
+			jen.Err().Op("!=").Nil().Op("||").Id("props").Op("==").Nil(),
+		).Block(jen.Return(jen.Err())),
+		jen.Line(),
+		jen.Id("require").Op(":=").Func().Params(jen.Id("when").String(), jen.Id("names").Op("...").String()).Error().Block(
+			jen.For(jen.List(jen.Id("_"), jen.Id("name")).Op(":=").Range().Id("names")).Block(
+				jen.If(
+					jen.List(jen.Id("_"), jen.Id("ok")).Op(":=").Id("props").Index(jen.Id("name")),
+					jen.Op("!").Id("ok"),
+				).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("%q is required in "+id+" %s"), jen.Id("name"), jen.Id("when"))),
+				),
+			),
+			jen.Return(jen.Nil()),
+		),
+	}
+	if matches {
+		body = append(body, jen.Var().Id("matches").Bool())
+	}
+	body = append(body, checks...)
+	body = append(body, jen.Return(jen.Nil()))
+
+	file.Commentf("UnmarshalJSON decodes a %s, and checks the properties required by its conditions.", id)
+	file.Func().Params(jen.Id("v").Op("*").Id(id)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(body...).Line()
+}
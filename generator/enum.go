@@ -0,0 +1,490 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+)
+
+// enumKind is the kind of the values of an enum.
+type enumKind string
+
+const (
+	enumString  enumKind = "string"
+	enumInteger enumKind = "integer"
+	enumNumber  enumKind = "number"
+	enumBoolean enumKind = "boolean"
+	// enumMixed is for values of different kinds, objects or arrays,
+	// generated as raw JSON.
+	enumMixed enumKind = "mixed"
+)
+
+// enumMember is a value of an enum, with its name and description if documented.
+type enumMember struct {
+	value       interface{}
+	name        string
+	description string
+}
+
+// enumMembers returns the values of an enum schema, nil if it is not one.
+//
+// The values are either listed by enum, named and described by the
+// x-enum-varnames and x-enum-descriptions extensions, or by a oneOf of const
+// schemas, named and described by their title and description, e.g.
+//
+//	{
+//		"oneOf": [
+//			{ "const": "a", "title": "Alpha", "description": "The first one." },
+//			{ "const": "b", "title": "Beta" }
+//		]
+//	}
+func enumMembers(schema *jsonschema.Schema) []enumMember {
+	if schema.Ref != "" {
+		return nil
+	}
+
+	var members []enumMember
+	if len(schema.Enum) > 0 {
+		for i, v := range schema.Enum {
+			member := enumMember{value: v}
+			if i < len(schema.EnumVarNames) {
+				member.name = schema.EnumVarNames[i]
+			}
+			if i < len(schema.EnumDescriptions) {
+				member.description = schema.EnumDescriptions[i]
+			}
+			members = append(members, member)
+		}
+		return members
+	}
+
+	if isConst(schema) {
+		return []enumMember{{value: schema.Const}}
+	}
+
+	for i := range schema.OneOf {
+		branch := &schema.OneOf[i]
+		if branch.Const == nil || !isConstOnly(branch) {
+			return nil
+		}
+		members = append(members, enumMember{
+			value:       branch.Const,
+			name:        branch.Title,
+			description: branch.Description,
+		})
+	}
+	return members
+}
+
+// isConst reports whether the schema is a const, generated as an enum type
+// of a single value, which is always the encoded value.
+func isConst(schema *jsonschema.Schema) bool {
+	return schema.Ref == "" && schema.Const != nil && len(schema.Enum) == 0
+}
+
+// isConstProperty reports whether the property is a const,
+// or refers to one, so that it is always encoded.
+func (g *Generator) isConstProperty(schema *jsonschema.Schema) bool {
+	if schema.Ref != "" {
+		chain, err := g.resolver.GetRefChain(schema)
+		if err != nil {
+			return false
+		}
+		schema = chain[len(chain)-1]
+	}
+	return isConst(schema)
+}
+
+// isConstOnly reports whether the schema only has a const beside annotations,
+// and a type matching the const.
+func isConstOnly(schema *jsonschema.Schema) bool {
+	only := *schema
+	only.Const = nil
+	if len(only.Type) == 1 && only.Type[0] == schema.SchemaType() {
+		only.Type = nil
+	}
+	return only.Ref == "" && !only.HasSiblingKeywords()
+}
+
+// isEnum reports whether the schema is generated as an enum type.
+func isEnum(schema *jsonschema.Schema) bool {
+	return len(enumMembers(schema)) > 0
+}
+
+// kindOfEnum returns the kind of the values of the enum, null aside,
+// and whether null is one of them.
+func kindOfEnum(schema *jsonschema.Schema) (kind enumKind, nullable bool) {
+	for _, member := range enumMembers(schema) {
+		var k enumKind
+		switch v := member.value.(type) {
+		case nil:
+			nullable = true
+			continue
+		case string:
+			k = enumString
+		case bool:
+			k = enumBoolean
+		case float64:
+			k = enumInteger
+			if v != float64(int64(v)) || schema.SchemaType() == jsonschema.TypeNumber {
+				k = enumNumber
+			}
+		default:
+			return enumMixed, nullable
+		}
+		switch {
+		case kind == "" || kind == k:
+			kind = k
+		case kind == enumInteger && k == enumNumber, kind == enumNumber && k == enumInteger:
+			kind = enumNumber
+		default:
+			return enumMixed, nullable
+		}
+	}
+	if kind == "" {
+		return enumMixed, nullable
+	}
+	return kind, nullable
+}
+
+// enumValues returns the values of the enum generated as constants,
+// named after the type and the name of the value if documented, or the value,
+// e.g. "StatusInProgress" for "in-progress".
+// A value without letter, such as a number, is named "<Type>Value<value>",
+// e.g. "<Type>ValueMinus2" for -2, or "<Type>Value<index>" when the name
+// is already taken.
+func (g *Generator) enumValues(schema *jsonschema.Schema, id string, kind enumKind) []enumMember {
+	var values []enumMember
+	taken := map[string]bool{}
+	for i, member := range enumMembers(schema) {
+		if member.value == nil && kind != enumMixed {
+			continue
+		}
+		name := g.toGolangName(member.name)
+		if name == "" {
+			value := enumValueString(member.value, kind)
+			if n, ok := member.value.(float64); ok && n < 0 {
+				value = "minus" + strings.TrimPrefix(value, "-")
+			}
+			name = g.toGolangName(value)
+		}
+		if name == "" || strings.HasPrefix(name, "_") || strings.HasPrefix(name, "Minus") {
+			name = "Value" + strings.TrimPrefix(name, "_")
+		}
+		for n := i + 1; name == "Value" || taken[name]; n++ {
+			name = "Value" + strconv.Itoa(n)
+		}
+		taken[name] = true
+		member.name = id + name
+		values = append(values, member)
+	}
+	return values
+}
+
+func enumValueString(v interface{}, kind enumKind) string {
+	switch v := v.(type) {
+	case string:
+		if kind != enumMixed {
+			return v
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return string(enumRawValue(v))
+}
+
+// enumRawValue returns the compact JSON of the value.
+func enumRawValue(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// enumLit returns the literal of the value in the base type of the enum.
+func enumLit(v interface{}, kind enumKind) jen.Code {
+	switch kind {
+	case enumString:
+		return jen.Lit(v.(string))
+	case enumInteger:
+		return jen.Lit(int(v.(float64)))
+	case enumNumber:
+		return jen.Lit(v.(float64))
+	case enumBoolean:
+		return jen.Lit(v.(bool))
+	}
+	return jen.Lit(string(enumRawValue(v)))
+}
+
+func enumZero(kind enumKind) jen.Code {
+	switch kind {
+	case enumString:
+		return jen.Lit("")
+	case enumBoolean:
+		return jen.False()
+	}
+	return jen.Lit(0)
+}
+
+func enumBaseType(kind enumKind) jen.Code {
+	switch kind {
+	case enumString:
+		return jen.String()
+	case enumInteger:
+		return jen.Int64()
+	case enumNumber:
+		return jen.Float64()
+	case enumBoolean:
+		return jen.Bool()
+	}
+	return jen.Qual("encoding/json", "RawMessage")
+}
+
+// generateEnumType returns the type referring to an inline enum,
+// which is generated as a named type after the current definition.
+// A nullable enum is referred to by a pointer.
+func (g *Generator) generateEnumType(schema *jsonschema.Schema) jen.Code {
+	t := jen.Id(g.nestedType(schema))
+	if kind, nullable := kindOfEnum(schema); nullable && kind != enumMixed {
+		return jen.Op("*").Add(t)
+	}
+	return t
+}
+
+// generateEnum generates a named type for the enum, with one constant per value,
+// the All<Type>Values slice, and the IsValid and UnmarshalJSON methods
+// rejecting unknown values.
+// Enums of mixed values are raw JSON values, compared in their compact form.
+// The MarshalJSON method of a const always encodes the constant.
+func (g *Generator) generateEnum(schema *jsonschema.Schema, id string, file *jen.File) {
+	kind, nullable := kindOfEnum(schema)
+	values := g.enumValues(schema, id, kind)
+
+	file.Type().Id(id).Add(enumBaseType(kind)).Line()
+
+	var defs, all []jen.Code
+	for _, v := range values {
+		if v.description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(v.description), "\n") {
+				defs = append(defs, jen.Comment(line))
+			}
+		}
+		if kind == enumMixed {
+			defs = append(defs, jen.Id(v.name).Op("=").Id(id).Call(enumLit(v.value, kind)))
+		} else {
+			defs = append(defs, jen.Id(v.name).Id(id).Op("=").Add(enumLit(v.value, kind)))
+		}
+		all = append(all, jen.Id(v.name))
+	}
+	if kind == enumMixed {
+		file.Var().Defs(defs...).Line()
+	} else {
+		file.Const().Defs(defs...).Line()
+	}
+
+	allValues := "All" + id + "Values"
+	file.Commentf("%s is all the values of %s.", allValues, id)
+	file.Var().Id(allValues).Op("=").Index().Id(id).Values(all...).Line()
+
+	equal := jen.Id("v").Op("==").Id("value")
+	if kind == enumMixed {
+		equal = jen.Qual("bytes", "Equal").Call(jen.Id("v"), jen.Id("value"))
+	}
+	file.Commentf("IsValid reports whether v is one of the values of %s.", id)
+	file.Func().Params(jen.Id("v").Id(id)).Id("IsValid").Params().Bool().Block(
+		jen.For(jen.List(jen.Id("_"), jen.Id("value")).Op(":=").Range().Id(allValues)).Block(
+			jen.If(equal).Block(jen.Return(jen.True())),
+		),
+		jen.Return(jen.False()),
+	).Line()
+
+	switch {
+	case isConst(schema):
+		value := jen.Add(enumBaseType(kind)).Call(jen.Id(values[0].name))
+		if kind == enumMixed {
+			value = jen.Qual("encoding/json", "RawMessage").Call(jen.Id(values[0].name))
+		}
+		file.Commentf("MarshalJSON encodes %s, whatever the value.", values[0].name)
+		file.Func().Params(jen.Id(id)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			jen.Return(jen.Qual("encoding/json", "Marshal").Call(value)),
+		).Line()
+	case kind == enumMixed:
+		file.Comment("MarshalJSON encodes the raw JSON value.")
+		file.Func().Params(jen.Id("v").Id(id)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			jen.Return(jen.Qual("encoding/json", "RawMessage").Call(jen.Id("v")).Dot("MarshalJSON").Call()),
+		).Line()
+	}
+
+	var decode []jen.Code
+	if kind == enumMixed {
+		decode = []jen.Code{
+			jen.Var().Id("buf").Qual("bytes", "Buffer"),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Compact").Call(jen.Op("&").Id("buf"), jen.Id("b")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.Id("value").Op(":=").Id(id).Call(jen.Id("buf").Dot("Bytes").Call()),
+		}
+	} else {
+		if nullable {
+			// null is the zero value
+			decode = append(decode, jen.If(jen.String().Call(jen.Id("b")).Op("==").Lit("null")).Block(
+				jen.Op("*").Id("v").Op("=").Id(id).Call(enumZero(kind)),
+				jen.Return(jen.Nil()),
+			))
+		}
+		decode = append(decode,
+			jen.Var().Id("raw").Add(enumBaseType(kind)),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("raw")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.Id("value").Op(":=").Id(id).Call(jen.Id("raw")),
+		)
+	}
+	file.Commentf("UnmarshalJSON decodes a value of %s, rejecting unknown values.", id)
+	file.Func().Params(jen.Id("v").Op("*").Id(id)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+		append(decode,
+			jen.If(jen.Op("!").Id("value").Dot("IsValid").Call()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("invalid "+id+" value: %s"), jen.Id("b"))),
+			),
+			jen.Op("*").Id("v").Op("=").Id("value"),
+			jen.Return(jen.Nil()),
+		)...,
+	).Line()
+}
+
+// nestedType returns the name of a subschema generated as a named type on
+// its own, and queues it to be generated after the current definition.
+func (g *Generator) nestedType(schema *jsonschema.Schema) string {
+	if !g.nestedQueued[schema] {
+		g.nestedQueued[schema] = true
+		g.nested = append(g.nested, schema)
+	}
+	return g.nestedTypeName(schema)
+}
+
+// nestedTypeName names a subschema generated as a named type on its own,
+// after the type of its definition, or of the enclosing nested type,
+// followed by its location in it,
+// e.g. "OrderStatus" for "/$defs/order/properties/status",
+// or "OrderTagsItem" for "/properties/tags/items" in order.json.
+// A name already given to a definition or another nested type
+// is suffixed by a number, e.g. "OrderStatus2".
+func (g *Generator) nestedTypeName(schema *jsonschema.Schema) string {
+	if name, ok := g.nestedNames[schema]; ok {
+		return name
+	}
+	if g.definitionNames == nil {
+		g.definitionNames = make(map[string]*jsonschema.Schema)
+		for _, root := range g.schemas {
+			defs := []*jsonschema.Schema{root}
+			for _, name := range sortedKeys(root.Defs) {
+				defs = append(defs, root.Defs[name])
+			}
+			for _, def := range defs {
+				name := g.SchemaTypeName(def)
+				if _, ok := g.definitionNames[name]; !ok && g.isDefinition(def) && !g.isOverridden(def) {
+					g.definitionNames[name] = def
+				}
+			}
+		}
+	}
+
+	base := g.locationTypeName(schema)
+	name := base
+	for n := 2; ; n++ {
+		def, isDef := g.definitionNames[name]
+		nested, isNested := g.nestedTaken[name]
+		if (!isDef || def == schema) && (!isNested || nested == schema) {
+			break
+		}
+		name = base + strconv.Itoa(n)
+	}
+	g.nestedNames[schema] = name
+	g.nestedTaken[name] = schema
+	return name
+}
+
+// locationTypeName names a nested subschema by its location,
+// see nestedTypeName.
+func (g *Generator) locationTypeName(schema *jsonschema.Schema) string {
+	if name, ok := g.typeNames[schema]; ok {
+		return name
+	}
+	if schema.Title != "" || schema.ID != "" || g.resolver == nil {
+		return g.SchemaTypeName(schema)
+	}
+	location, ok := g.resolver.locations[schema]
+	if !ok || location.Fragment == "" {
+		return g.SchemaTypeName(schema)
+	}
+
+	resource := location
+	resource.Fragment = ""
+	root, ok := g.resolver.resources[resource.String()]
+	if !ok {
+		return g.SchemaTypeName(schema)
+	}
+	parent := g.SchemaTypeName(root)
+	tokens := strings.Split(location.Fragment, "/")[1:]
+	rest := tokens
+	// the nearest enclosing definition, or named nested type
+	for i := len(tokens) - 1; i >= 1; i-- {
+		prefix := location
+		prefix.Fragment = "/" + strings.Join(tokens[:i], "/")
+		enclosing, ok := g.resolver.pathToSchema[prefix.String()]
+		if !ok {
+			continue
+		}
+		if name, ok := g.typeNames[enclosing]; ok {
+			parent, rest = name, tokens[i:]
+			break
+		}
+		if i >= 2 && tokens[i-2] == "$defs" {
+			parent, rest = g.SchemaTypeName(enclosing), tokens[i:]
+			break
+		}
+	}
+
+	var name bytes.Buffer
+	name.WriteString(parent)
+	for i := 0; i < len(rest); i++ {
+		token := rest[i]
+		// the tokens naming the subschema itself are kept,
+		// e.g. for a $ref to "#/allOf/0"
+		remaining := len(rest) - i
+		switch {
+		case token == "properties", token == "$defs" && remaining > 1:
+			continue
+		case (token == "allOf" || token == "dependentSchemas") && remaining > 2:
+			// the members of an allOf and the conditional subschemas
+			// are merged into the parent
+			i++
+			continue
+		case (token == "then" || token == "else") && remaining > 1:
+			continue
+		}
+		switch token {
+		case "prefixItems":
+			if i+1 < len(rest) {
+				i++
+				name.WriteString("V" + rest[i])
+			}
+			continue
+		case "items":
+			token = "item"
+		}
+		if _, err := strconv.Atoi(token); err == nil {
+			// an index, after the name of the keyword, e.g. "AllOf0"
+			name.WriteString(token)
+			continue
+		}
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+		name.WriteString(g.toGolangName(token))
+	}
+	return name.String()
+}
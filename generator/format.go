@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+)
+
+const formatsPackage = "github.com/RyoJerryYu/go-jsonschema/formats"
+
+// DefaultFormatTypes is the Go types of the string formats by default,
+// the other formats being strings.
+var DefaultFormatTypes = map[string]string{
+	"date-time": "time.Time",
+}
+
+// PackageFormatTypes is the Go types of the string formats with the
+// FormatsPackage option. The types of the formats package are encoded as
+// JSON strings, the generated code importing the package.
+var PackageFormatTypes = map[string]string{
+	"date":     formatsPackage + ".Date",
+	"byte":     "[]byte",
+	"uri":      formatsPackage + ".URL",
+	"uuid":     formatsPackage + ".UUID",
+	"duration": formatsPackage + ".Duration",
+}
+
+// parseGoType parses a Go type, either qualified by its import path,
+// e.g. "github.com/google/uuid.UUID", or a predeclared or local type,
+// e.g. "string", optionally prefixed by "*", "[]" or "[<n>]".
+func parseGoType(spec string) (jen.Code, error) {
+	switch {
+	case strings.HasPrefix(spec, "*"):
+		t, err := parseGoType(spec[1:])
+		if err != nil {
+			return nil, err
+		}
+		return jen.Op("*").Add(t), nil
+	case strings.HasPrefix(spec, "["):
+		end := strings.Index(spec, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid Go type %q", spec)
+		}
+		t, err := parseGoType(spec[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		if end == 1 {
+			return jen.Index().Add(t), nil
+		}
+		n, err := strconv.Atoi(spec[1:end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid Go type %q", spec)
+		}
+		return jen.Index(jen.Lit(n)).Add(t), nil
+	}
+
+	path, name := "", spec
+	if i := strings.LastIndex(spec, "."); i >= 0 {
+		path, name = spec[:i], spec[i+1:]
+		if path == "" || strings.HasSuffix(path, "/") {
+			return nil, fmt.Errorf("invalid Go type %q", spec)
+		}
+	}
+	if !isIdentifier(name) {
+		return nil, fmt.Errorf("invalid Go type %q", spec)
+	}
+	if path == "" {
+		return jen.Id(name), nil
+	}
+	return jen.Qual(path, name), nil
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// formatTypes returns the Go types of the string formats,
+// the options overriding the defaults.
+func formatTypes(opts *GeneratorOptions) (map[string]string, error) {
+	specs := make(map[string]string)
+	for format, spec := range DefaultFormatTypes {
+		specs[format] = spec
+	}
+	if opts.FormatsPackage {
+		for format, spec := range PackageFormatTypes {
+			specs[format] = spec
+		}
+	}
+	for format, spec := range opts.FormatTypes {
+		specs[format] = spec
+	}
+
+	for format, spec := range specs {
+		if spec == "" {
+			// kept as a string
+			delete(specs, format)
+			continue
+		}
+		if _, err := parseGoType(spec); err != nil {
+			return nil, fmt.Errorf("format %s: %w", format, err)
+		}
+	}
+	return specs, nil
+}
+
+// generateFormatType returns the Go type of the format of a string schema,
+// nil if it has none. A base64 content encoding is the "byte" format.
+// Optional properties are pointers, unless the type is a slice.
+func (g *Generator) generateFormatType(schema *jsonschema.Schema, required bool) jen.Code {
+	format := schema.Format
+	if format == "" && schema.ContentEncoding == "base64" {
+		format = "byte"
+	}
+	spec, ok := g.formatTypes[format]
+	if !ok {
+		return nil
+	}
+	// validated by NewGenerator
+	t, _ := parseGoType(spec)
+	if !required && !strings.HasPrefix(spec, "[]") {
+		return jen.Op("*").Add(t)
+	}
+	return t
+}
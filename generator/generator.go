@@ -20,11 +20,21 @@ func GenerateRoot(opts *GeneratorOptions, f *jen.File, schemas ...*jsonschema.Sc
 		return errors.New(err)
 	}
 
+	if err := generator.CheckRefs(); err != nil {
+		return err
+	}
+
+	if err := generator.CheckAllOf(); err != nil {
+		return err
+	}
+
 	f.HeaderComment("Code generated by go-jsonschema. DO NOT EDIT.")
+	f.ImportName(formatsPackage, "formats")
 
 	for _, schema := range schemas {
-		// if the root schema is a reference, do not generate it
-		if schema.Ref == "" {
+		// if the root schema is a reference, do not generate it,
+		// unless it extends a schema through $dynamicAnchor
+		if schema.Ref == "" || generator.isDynamicExtension(schema) {
 			generator.GenerateDef(schema, f)
 		}
 
@@ -46,12 +56,57 @@ type GeneratorOptions struct {
 	WithAdditionalProperties bool
 	// will apply the upper case rule to the property names
 	UpperPropertyNames []string
+	// will fail on unresolved references, instead of generating json.RawMessage
+	StrictRefs bool
+	// will use the final type of a chain of references, instead of
+	// generating type aliases for the intermediate schemas
+	CollapseRefChains bool
+	// maps the string formats to the types of PackageFormatTypes too,
+	// instead of strings
+	FormatsPackage bool
+	// overrides the Go types of the string formats of DefaultFormatTypes,
+	// and PackageFormatTypes, e.g. "uuid": "github.com/google/uuid.UUID",
+	// an empty type keeping the format a string
+	FormatTypes map[string]string
+	// maps the $id of schemas, or their URI with a JSON pointer fragment,
+	// to existing Go types qualified by their import path, e.g.
+	// "https://corp/schemas/money.json": "github.com/corp/lib/money.Amount",
+	// the schemas are not generated
+	TypeOverrides map[string]string
 }
 
 type Generator struct {
 	opts     *GeneratorOptions
 	schemas  []*jsonschema.Schema
 	resolver *RefResolver
+	// dynamicScope is the schema resources entered while generating
+	// the current definition, from the outermost one
+	dynamicScope []string
+	// nested is the subschemas to generate as named types
+	// after the current definition, e.g. inline enums
+	nested       []*jsonschema.Schema
+	nestedQueued map[*jsonschema.Schema]bool
+	// merging is the schemas being merged, to detect cyclic allOf
+	merging map[*jsonschema.Schema]bool
+	// typeNames is the names given to nested subschemas
+	// instead of their location, e.g. the branches of a union
+	typeNames map[*jsonschema.Schema]string
+	// nestedNames is the names of the nested types, unique among
+	// the definitionNames and the nestedTaken names, set on first use
+	nestedNames     map[*jsonschema.Schema]string
+	nestedTaken     map[string]*jsonschema.Schema
+	definitionNames map[string]*jsonschema.Schema
+	// refTargets is the schemas other than definitions a $ref refers to,
+	// set on first use
+	refTargets map[*jsonschema.Schema]bool
+	// current is the schema generateDef is generating
+	current *jsonschema.Schema
+	// formatTypes is the Go types of the string formats
+	formatTypes map[string]string
+	// overrides is the Go types the schemas are mapped to,
+	// and overrideURIs by URI, for the schemas which are not loaded
+	overrides    map[*jsonschema.Schema]string
+	overrideURIs map[string]string
 }
 
 func NewGenerator(opts *GeneratorOptions, schemas ...*jsonschema.Schema) (*Generator, error) {
@@ -59,14 +114,68 @@ func NewGenerator(opts *GeneratorOptions, schemas ...*jsonschema.Schema) (*Gener
 	if err != nil {
 		return nil, errors.New(err)
 	}
+	formatTypes, err := formatTypes(opts)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	overrideURIs, overrides, err := typeOverrides(opts, resolver)
+	if err != nil {
+		return nil, errors.New(err)
+	}
 	generator := &Generator{
-		opts:     opts,
-		schemas:  schemas,
-		resolver: resolver,
+		opts:         opts,
+		schemas:      schemas,
+		resolver:     resolver,
+		nestedQueued: make(map[*jsonschema.Schema]bool),
+		merging:      make(map[*jsonschema.Schema]bool),
+		typeNames:    make(map[*jsonschema.Schema]string),
+		nestedNames:  make(map[*jsonschema.Schema]string),
+		nestedTaken:  make(map[string]*jsonschema.Schema),
+		formatTypes:  formatTypes,
+		overrides:    overrides,
+		overrideURIs: overrideURIs,
 	}
 	return generator, nil
 }
 
+// Registry returns the index of the schemas the generator resolves references with.
+func (g *Generator) Registry() *Registry {
+	return &Registry{resolver: g.resolver}
+}
+
+// CheckRefs resolves the $ref and $dynamicRef of all the schemas and
+// their subschemas, and returns an error listing all the cyclic $ref chains,
+// and with the StrictRefs option, all the unresolved references.
+func (g *Generator) CheckRefs() error {
+	var msgs []string
+	var check func(schema *jsonschema.Schema)
+	check = func(schema *jsonschema.Schema) {
+		if _, _, overridden := g.refOverride(schema); schema.Ref != "" && !overridden {
+			_, err := g.resolver.GetRefChain(schema)
+			var unresolved *UnresolvedRefError
+			if err != nil && (g.opts.StrictRefs || !errors.As(err, &unresolved)) {
+				msgs = append(msgs, err.Error())
+			}
+		}
+		if schema.DynamicRef != "" && g.opts.StrictRefs {
+			if _, err := g.resolver.GetSchemaByDynamicReference(schema, nil); err != nil {
+				msgs = append(msgs, err.Error())
+			}
+		}
+		schema.Subschemas(func(_ []string, subschema *jsonschema.Schema) {
+			check(subschema)
+		})
+	}
+	for _, schema := range g.schemas {
+		check(schema)
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.Errorf("%d invalid references:\n\t%s", len(msgs), strings.Join(msgs, "\n\t"))
+}
+
 func refName(ref string) string {
 	prefix := "#/$defs/"
 	if !strings.HasPrefix(ref, prefix) {
@@ -79,14 +188,179 @@ func (g *Generator) resolveRef(def *jsonschema.Schema) (*jsonschema.Schema, erro
 	return g.resolver.GetSchemaByReference(def)
 }
 
-func (g *Generator) generateStruct(schema *jsonschema.Schema) jen.Code {
+// extendsRef reports whether the schema has properties beside a $ref to
+// an object, which extend the referenced type, merged like an allOf.
+// An existing Go type the reference is mapped to can not be extended.
+func (g *Generator) extendsRef(schema *jsonschema.Schema) bool {
+	if schema.Ref == "" || len(schema.Properties) == 0 {
+		return false
+	}
+	if _, _, ok := g.refOverride(schema); ok {
+		return true
+	}
+	chain, err := g.resolver.GetRefChain(schema)
+	if err != nil {
+		return false
+	}
+	target := chain[len(chain)-1]
+	return target.SchemaType() == jsonschema.TypeObject || g.isMergedObject(target)
+}
+
+// isDefinition reports whether the schema is generated by GenerateRoot
+// on its own, being a root schema or one of their $defs.
+func (g *Generator) isDefinition(schema *jsonschema.Schema) bool {
+	for _, root := range g.schemas {
+		if root == schema {
+			return root.Ref == "" || g.isDynamicExtension(root)
+		}
+		for _, def := range root.Defs {
+			if def == schema {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refTypeName returns the name of the type of a referred schema.
+// A reference to another location than a definition,
+// e.g. "#/properties/address", generates the target as a nested type.
+func (g *Generator) refTypeName(schema *jsonschema.Schema) string {
+	if g.isDefinition(schema) {
+		return g.SchemaTypeName(schema)
+	}
+	return g.nestedType(schema)
+}
+
+// isRefTarget reports whether a $ref refers to the schema, which is not a
+// definition, the schema being generated as a nested type used by the
+// references and by the schema's own location alike.
+func (g *Generator) isRefTarget(schema *jsonschema.Schema) bool {
+	if g.refTargets == nil {
+		g.refTargets = make(map[*jsonschema.Schema]bool)
+		var walk func(schema *jsonschema.Schema)
+		walk = func(schema *jsonschema.Schema) {
+			if schema.Ref != "" && !g.extendsRef(schema) {
+				if chain, err := g.resolver.GetRefChain(schema); err == nil {
+					named := chain[0]
+					if g.opts.CollapseRefChains {
+						named = chain[len(chain)-1]
+					}
+					if !g.isDefinition(named) {
+						g.refTargets[named] = true
+					}
+				}
+			}
+			schema.Subschemas(func(_ []string, subschema *jsonschema.Schema) {
+				walk(subschema)
+			})
+		}
+		for _, schema := range g.schemas {
+			walk(schema)
+		}
+	}
+	return g.refTargets[schema]
+}
+
+// enterResource pushes the schema resource of schema to the dynamic scope,
+// and returns a function popping it.
+func (g *Generator) enterResource(schema *jsonschema.Schema) func() {
+	resource, ok := g.resolver.ResourceOf(schema)
+	if !ok {
+		return func() {}
+	}
+	g.dynamicScope = append(g.dynamicScope, resource)
+	return func() {
+		g.dynamicScope = g.dynamicScope[:len(g.dynamicScope)-1]
+	}
+}
+
+// isDynamicExtension reports whether the schema extends the schema it
+// refers to by redefining its $dynamicAnchor, e.g.
+//
+//	{
+//		"$dynamicAnchor": "node",
+//		"$ref": "tree.json",
+//		"properties": { ... }
+//	}
+//
+// with tree.json declaring "$dynamicAnchor": "node", and referring to
+// its nodes by "$dynamicRef": "#node".
+func (g *Generator) isDynamicExtension(schema *jsonschema.Schema) bool {
+	if schema.Ref == "" || schema.DynamicAnchor == "" {
+		return false
+	}
+	base, _, err := g.resolver.resolve(schema, schema.Ref)
+	if err != nil || base == nil {
+		return false
+	}
+	return base.DynamicAnchor == schema.DynamicAnchor
+}
+
+// extendedSchemaType returns the type of the schema,
+// inherited from the extended schema for a dynamic extension.
+func (g *Generator) extendedSchemaType(schema *jsonschema.Schema) jsonschema.Type {
+	seen := map[*jsonschema.Schema]bool{}
+	for schema != nil && !seen[schema] && schema.SchemaType() == "" && g.isDynamicExtension(schema) {
+		seen[schema] = true
+		schema, _, _ = g.resolver.resolve(schema, schema.Ref)
+	}
+	if schema == nil {
+		return ""
+	}
+	return schema.SchemaType()
+}
+
+// generateDynamicExtension generates a concrete type for a dynamic extension,
+// with the properties of the extended schemas, in which the $dynamicRef
+// resolve to the extension.
+func (g *Generator) generateDynamicExtension(schema *jsonschema.Schema) jen.Code {
+	chain := []*jsonschema.Schema{schema}
+	seen := map[*jsonschema.Schema]bool{schema: true}
+	for g.isDynamicExtension(chain[len(chain)-1]) {
+		base, _, _ := g.resolver.resolve(chain[len(chain)-1], chain[len(chain)-1].Ref)
+		if seen[base] {
+			break // cyclic extensions
+		}
+		seen[base] = true
+		chain = append(chain, base)
+	}
+
+	// the extension is the outermost scope, so that it takes precedence
+	for _, s := range chain[1:] {
+		defer g.enterResource(s)()
+	}
+
+	merged := *chain[len(chain)-1]
+	merged.Properties = make(map[string]*jsonschema.Schema)
+	merged.Required = nil
+	for i := len(chain) - 1; i >= 0; i-- {
+		s := chain[i]
+		for name, prop := range s.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, s.Required...)
+		if len(s.Type) > 0 {
+			merged.Type = s.Type
+		}
+		if s.AdditionalProperties != nil {
+			merged.AdditionalProperties = s.AdditionalProperties
+		}
+	}
+	merged.Ref = ""
+	return g.generateSchemaType(&merged, true)
+}
+
+// generateStruct generates a struct of the schema properties,
+// after the embedded fields.
+func (g *Generator) generateStruct(schema *jsonschema.Schema, embedded ...jen.Code) jen.Code {
 	var names []string
 	for name := range schema.Properties {
 		names = append(names, name)
 	}
 	sort.Strings(names)
 
-	var fields []jen.Code
+	fields := append([]jen.Code{}, embedded...)
 	for _, name := range names {
 		prop := schema.Properties[name]
 		required := schema.IsRequired(name)
@@ -94,7 +368,8 @@ func (g *Generator) generateStruct(schema *jsonschema.Schema) jen.Code {
 
 		id := g.toGolangName(name)
 		jsonTag := name
-		if !required {
+		// a const is always encoded
+		if !required && !g.isConstProperty(prop) {
 			jsonTag += ",omitempty"
 		}
 		field := jen.Id(id).Add(t).Tag(map[string]string{"json": jsonTag})
@@ -137,20 +412,91 @@ func (g *Generator) generateSchemaType(schema *jsonschema.Schema, required bool)
 		schema = &jsonschema.Schema{}
 	}
 
+	if spec, ok := g.overrides[schema]; ok {
+		return generateOverrideType(spec, schema, required)
+	}
+
+	if spec, target, ok := g.refOverride(schema); ok {
+		return generateOverrideType(spec, target, required)
+	}
+
 	refName := refName(schema.Ref)
 	if refName != "" {
-		var err error
-		schema, err = g.resolveRef(schema)
+		chain, err := g.resolver.GetRefChain(schema)
+		if err != nil {
+			return jen.Qual("encoding/json", "RawMessage")
+		}
+		target := chain[len(chain)-1]
+		named := chain[0]
+		if g.opts.CollapseRefChains {
+			named = target
+		}
+		if g.extendsRef(schema) {
+			if g.isConditional(schema) {
+				// a named type, to check the conditions when decoded
+				t := jen.Id(g.nestedType(schema))
+				if !required {
+					return jen.Op("*").Add(t)
+				}
+				return t
+			}
+			return g.generateAllOf(schema, required)
+		}
+		isObject := target.SchemaType() == jsonschema.TypeObject || g.isMergedObject(target)
+		var t jen.Code = jen.Id(g.refTypeName(named))
+		if !required && (isObject || isUnion(target) || isTuple(target)) {
+			t = jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if schema.DynamicRef != "" {
+		target, err := g.resolver.GetSchemaByDynamicReference(schema, g.dynamicScope)
 		if err != nil {
 			return jen.Qual("encoding/json", "RawMessage")
 		}
-		t := jen.Id(g.SchemaTypeName(schema))
-		if !required && schema.SchemaType() == jsonschema.TypeObject {
+		t := jen.Id(g.refTypeName(target))
+		if !required && g.extendedSchemaType(target) == jsonschema.TypeObject {
 			t = jen.Op("*").Add(t)
 		}
 		return t
 	}
 
+	if isEnum(schema) {
+		return g.generateEnumType(schema)
+	}
+
+	if isUnion(schema) || isTuple(schema) {
+		t := jen.Id(g.nestedType(schema))
+		if !required {
+			return jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if g.isConditional(schema) {
+		// a named type, to check the conditions when decoded
+		t := jen.Id(g.nestedType(schema))
+		if !required {
+			return jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if schema != g.current && g.isRefTarget(schema) {
+		// the same named type as the references to the schema
+		t := jen.Id(g.nestedType(schema))
+		isObject := schema.SchemaType() == jsonschema.TypeObject || g.isMergedObject(schema)
+		if !required && isObject {
+			return jen.Op("*").Add(t)
+		}
+		return t
+	}
+
+	if len(schema.AllOf) > 0 {
+		return g.generateAllOf(schema, required)
+	}
+
 	if subschema, ok := schema.UnwrapNullableSchema(); ok {
 		if subschema.SchemaType() == jsonschema.TypeArray {
 			return jen.Add(g.generateSchemaType(subschema, true))
@@ -168,6 +514,9 @@ func (g *Generator) generateSchemaType(schema *jsonschema.Schema, required bool)
 	case jsonschema.TypeNumber:
 		return jen.Qual("encoding/json", "Number")
 	case jsonschema.TypeString:
+		if t := g.generateFormatType(schema, required); t != nil {
+			return t
+		}
 		return jen.String()
 	case jsonschema.TypeInteger:
 		return jen.Int64()
@@ -182,26 +531,113 @@ func (g *Generator) generateSchemaType(schema *jsonschema.Schema, required bool)
 	}
 }
 
+// addDocComment adds the description of the schema
+// as the doc comment of the next declaration.
+func addDocComment(file *jen.File, schema *jsonschema.Schema) {
+	if schema.Description == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(schema.Description), "\n") {
+		file.Comment(line)
+	}
+}
+
+// GenerateDef generates the type of the schema,
+// followed by the types of its subschemas generated on their own.
 func (g *Generator) GenerateDef(schema *jsonschema.Schema, file *jen.File) {
-	id := g.SchemaTypeName(schema)
+	if g.isOverridden(schema) {
+		// mapped to an existing type
+		return
+	}
+	g.nestedQueued[schema] = true
+	g.generateDef(g.SchemaTypeName(schema), schema, file)
+	for len(g.nested) > 0 {
+		nested := g.nested[0]
+		g.nested = g.nested[1:]
+		g.generateDef(g.nestedTypeName(nested), nested, file)
+	}
+}
+
+func (g *Generator) generateDef(id string, schema *jsonschema.Schema, file *jen.File) {
+	defer g.enterResource(schema)()
+	g.current = schema
+
+	addDocComment(file, schema)
+
+	if isEnum(schema) {
+		g.generateEnum(schema, id, file)
+		return
+	}
+
+	if union := g.discriminatorOf(schema); union != nil {
+		g.generateDiscriminatedUnion(id, union, file)
+		return
+	}
+
+	if isUnion(schema) {
+		g.generateUntaggedUnion(schema, id, file)
+		return
+	}
+
+	if isTuple(schema) {
+		g.generateTuple(schema, id, file)
+		return
+	}
+
+	if g.isDynamicExtension(schema) {
+		file.Type().Id(id).Add(g.generateDynamicExtension(schema)).Line()
+		return
+	}
+
+	if len(schema.AllOf) > 0 && schema.Ref == "" || g.isConditional(schema) {
+		if merged, err := g.mergeAllOf(schema); err == nil && merged.alias != nil {
+			// an alias of the single referred schema
+			file.Type().Id(id).Op("=").Add(g.generateSchemaType(merged.alias, true)).Line()
+			return
+		}
+		file.Type().Id(id).Add(g.generateAllOf(schema, true)).Line()
+		if g.isConditional(schema) && g.isMergedObject(schema) {
+			g.generateConditions(schema, id, file)
+		}
+		return
+	}
+
+	if schema.IsPureRef() {
+		if _, err := g.resolver.GetRefChain(schema); err != nil {
+			file.Type().Id(id).Qual("encoding/json", "RawMessage").Line()
+			return
+		}
+		if !g.opts.CollapseRefChains {
+			// an alias of the next schema in the chain
+			file.Type().Id(id).Op("=").Add(g.generateSchemaType(schema, true)).Line()
+		}
+		return
+	}
 
 	if schema.Ref == "" && schema.SchemaType() == "" {
 		file.Type().Id(id).Struct(
 			jen.Qual("encoding/json", "RawMessage"),
 		).Line()
 
-		var children []jsonschema.Schema
-		children = append(children, schema.AllOf...)
-		children = append(children, schema.AnyOf...)
-		children = append(children, schema.OneOf...)
+		var children []*jsonschema.Schema
+		for _, choices := range [][]jsonschema.Schema{schema.AllOf, schema.AnyOf, schema.OneOf} {
+			for i := range choices {
+				children = append(children, &choices[i])
+			}
+		}
 		if schema.Then != nil {
-			children = append(children, *schema.Then)
+			children = append(children, schema.Then)
 		}
 		if schema.Else != nil {
-			children = append(children, *schema.Else)
+			children = append(children, schema.Else)
+		}
+		var dependencies []string
+		for name := range schema.DependentSchemas {
+			dependencies = append(dependencies, name)
 		}
-		for _, child := range schema.DependentSchemas {
-			children = append(children, child)
+		sort.Strings(dependencies)
+		for _, name := range dependencies {
+			children = append(children, schema.DependentSchemas[name])
 		}
 
 		for _, child := range children {
@@ -210,7 +646,7 @@ func (g *Generator) GenerateDef(schema *jsonschema.Schema, file *jen.File) {
 				continue
 			}
 
-			t := g.generateSchemaType(&child, false)
+			t := g.generateSchemaType(child, false)
 
 			file.Func().Params(
 				jen.Id("v").Id(id),
@@ -0,0 +1,453 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+)
+
+func TestStrictRefs(t *testing.T) {
+	schemas := mustLoadSchemas(t, `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"ok": { "$ref": "#/$defs/a" },
+			"typo": { "$ref": "#/$defs/b" },
+			"list": { "items": { "$ref": "other.json" } }
+		},
+		"$defs": { "a": { "type": "string" } }
+	}`)
+
+	err := GenerateRoot(&GeneratorOptions{}, jen.NewFile("test"), schemas...)
+	if err != nil {
+		t.Fatalf("expected no error without strict refs, got %v", err)
+	}
+
+	err = GenerateRoot(&GeneratorOptions{StrictRefs: true}, jen.NewFile("test"), schemas...)
+	if err == nil {
+		t.Fatal("expected unresolved references")
+	}
+	for _, expected := range []string{
+		"2 invalid references",
+		"https://example.com/root.json#/properties/typo: reference not found: #/$defs/b (resolved as https://example.com/root.json#/$defs/b)",
+		"https://example.com/root.json#/properties/list/items: reference not found: other.json (resolved as https://example.com/other.json)",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in %v", expected, err)
+		}
+	}
+}
+
+func generateString(t *testing.T, opts *GeneratorOptions, docs ...string) string {
+	t.Helper()
+	f := jen.NewFile("test")
+	if err := GenerateRoot(opts, f, mustLoadSchemas(t, docs...)...); err != nil {
+		t.Fatal(err)
+	}
+	return f.GoString()
+}
+
+func TestRefChains(t *testing.T) {
+	doc := `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"value": { "$ref": "#/$defs/a" }
+		},
+		"$defs": {
+			"a": { "$ref": "#/$defs/b", "description": "only an annotation" },
+			"b": { "$ref": "#/$defs/c" },
+			"c": { "type": "object", "properties": { "x": { "type": "string" } } }
+		}
+	}`
+
+	t.Run("aliases", func(t *testing.T) {
+		out := generateString(t, &GeneratorOptions{}, doc)
+		for _, expected := range []string{
+			"Value *A `json:\"value,omitempty\"`",
+			"type A = B",
+			"type B = C",
+			"type C struct",
+		} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("expected %q in:\n%s", expected, out)
+			}
+		}
+	})
+
+	t.Run("collapsed", func(t *testing.T) {
+		out := generateString(t, &GeneratorOptions{CollapseRefChains: true}, doc)
+		if !strings.Contains(out, "Value *C `json:\"value,omitempty\"`") {
+			t.Errorf("expected the final type in:\n%s", out)
+		}
+		if strings.Contains(out, "type A") || strings.Contains(out, "type B") {
+			t.Errorf("expected intermediate types to be collapsed in:\n%s", out)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		schemas := mustLoadSchemas(t, `{
+			"$id": "https://example.com/root.json",
+			"$defs": {
+				"a": { "$ref": "#/$defs/b" },
+				"b": { "$ref": "#/$defs/a" }
+			}
+		}`)
+		err := GenerateRoot(&GeneratorOptions{}, jen.NewFile("test"), schemas...)
+		if err == nil || !strings.Contains(err.Error(), "cyclic references: ") {
+			t.Errorf("expected cyclic references, got %v", err)
+		}
+	})
+}
+
+func TestRefWithSiblingKeywords(t *testing.T) {
+	out := generateString(t, &GeneratorOptions{}, `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"inline": {
+				"$ref": "#/$defs/base",
+				"properties": { "more": { "type": "integer" } }
+			}
+		},
+		"$defs": {
+			"base": { "type": "object", "properties": { "id": { "type": "string" } } },
+			"extended": {
+				"$ref": "#/$defs/base",
+				"description": "An extended base.",
+				"properties": { "extra": { "type": "string" } },
+				"required": ["extra"]
+			}
+		}
+	}`)
+
+	for _, expected := range []string{
+		"// An extended base.\ntype Extended struct {\n\tBase\n\tExtra string `json:\"extra\"`\n}",
+		"Inline *struct {\n\t\tBase\n\t\tMore int64 `json:\"more,omitempty\"`\n\t} `json:\"inline,omitempty\"`",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestEnums(t *testing.T) {
+	out := generateString(t, &GeneratorOptions{}, `{
+		"$id": "https://example.com/root.json",
+		"$defs": {
+			"order": {
+				"title": "purchase order",
+				"type": "object",
+				"properties": {
+					"state": { "enum": ["open", "closed"] },
+					"score": { "type": "number", "enum": [0.5, 1] },
+					"flag": { "enum": [true, false, null] },
+					"delta": { "enum": [2, -2, -0.5] },
+					"code": { "enum": ["value3", "x", "X"] }
+				}
+			}
+		}
+	}`)
+
+	for _, expected := range []string{
+		"State PurchaseOrderState `json:\"state,omitempty\"`",
+		"Score PurchaseOrderScore `json:\"score,omitempty\"`",
+		"Flag  *PurchaseOrderFlag `json:\"flag,omitempty\"`",
+		"type PurchaseOrderState string",
+		"PurchaseOrderStateOpen   PurchaseOrderState = \"open\"",
+		"type PurchaseOrderScore float64",
+		"PurchaseOrderScoreValue05 PurchaseOrderScore = 0.5",
+		"PurchaseOrderScoreValue1  PurchaseOrderScore = 1.0",
+		"type PurchaseOrderFlag bool",
+		"PurchaseOrderFlagTrue  PurchaseOrderFlag = true",
+		"PurchaseOrderDeltaValue2       PurchaseOrderDelta = 2.0",
+		"PurchaseOrderDeltaValueMinus2  PurchaseOrderDelta = -2.0",
+		"PurchaseOrderDeltaValueMinus05 PurchaseOrderDelta = -0.5",
+		"PurchaseOrderCodeValue4 PurchaseOrderCode = \"X\"",
+		"var AllPurchaseOrderStateValues = []PurchaseOrderState{PurchaseOrderStateOpen, PurchaseOrderStateClosed}",
+		"func (v PurchaseOrderState) IsValid() bool",
+		"func (v *PurchaseOrderState) UnmarshalJSON(b []byte) error",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestDocumentedEnums(t *testing.T) {
+	out := generateString(t, &GeneratorOptions{}, `{
+		"$id": "https://example.com/root.json",
+		"$defs": {
+			"code": {
+				"type": "integer",
+				"enum": [200, 404],
+				"x-enum-varnames": ["ok", "not found"],
+				"x-enum-descriptions": ["The request succeeded."]
+			},
+			"op": {
+				"type": "string",
+				"oneOf": [
+					{ "const": "+", "title": "add", "description": "Adds the operands.\nCommutative." },
+					{ "const": "-", "title": "subtract" }
+				]
+			}
+		}
+	}`)
+
+	for _, expected := range []string{
+		"type Code int64",
+		"// The request succeeded.\n\tCodeOk       Code = 200\n\tCodeNotFound Code = 404",
+		"type Op string",
+		"// Adds the operands.\n\t// Commutative.\n\tOpAdd      Op = \"+\"\n\tOpSubtract Op = \"-\"",
+		"var AllOpValues = []Op{OpAdd, OpSubtract}",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestNestedTypeNameCollisions(t *testing.T) {
+	out := generateString(t, &GeneratorOptions{}, `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"status": { "enum": ["open", "closed"] },
+			"point": { "prefixItems": [{ "type": "number" }, { "type": "number" }] }
+		},
+		"$defs": {
+			"rootStatus": { "type": "string" },
+			"rootPoint": { "type": "object", "properties": { "x": { "type": "number" } } }
+		}
+	}`)
+
+	for _, expected := range []string{
+		"Status RootStatus2 `json:\"status,omitempty\"`",
+		"Point  *RootPoint2 `json:\"point,omitempty\"`",
+		"type RootStatus2 string",
+		"RootStatus2Open   RootStatus2 = \"open\"",
+		"type RootPoint2 struct",
+		"type RootStatus string",
+		"type RootPoint struct",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+	for _, name := range []string{"RootStatus", "RootPoint"} {
+		if n := strings.Count(out, "type "+name+" "); n != 1 {
+			t.Errorf("expected one %s type, got %d", name, n)
+		}
+	}
+}
+
+func TestAllOfConflicts(t *testing.T) {
+	schemas := mustLoadSchemas(t, `{
+		"$id": "https://example.com/root.json",
+		"$defs": {
+			"person": {
+				"type": "object",
+				"properties": { "name": { "type": "string" } }
+			},
+			"ok": {
+				"allOf": [
+					{ "$ref": "#/$defs/person" },
+					{ "properties": { "name": { "minLength": 1 } } }
+				]
+			},
+			"conflict": {
+				"allOf": [
+					{ "$ref": "#/$defs/person" },
+					{ "properties": { "name": { "type": "integer" } } }
+				]
+			}
+		}
+	}`)
+
+	err := GenerateRoot(&GeneratorOptions{}, jen.NewFile("test"), schemas...)
+	if err == nil {
+		t.Fatal("expected conflicting properties")
+	}
+	for _, expected := range []string{
+		"1 invalid allOf",
+		`https://example.com/root.json#/$defs/conflict: allOf: conflicting types for property "name": string in https://example.com/root.json#/$defs/person, integer in https://example.com/root.json#/$defs/conflict/allOf/1`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in %v", expected, err)
+		}
+	}
+}
+
+func TestFormatTypes(t *testing.T) {
+	doc := `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"id": { "type": "string", "format": "uuid" },
+			"at": { "type": "string", "format": "date-time" },
+			"mac": { "type": "string", "format": "mac" }
+		},
+		"required": ["id"]
+	}`
+	out := generateString(t, &GeneratorOptions{FormatTypes: map[string]string{
+		"uuid":      "github.com/google/uuid.UUID",
+		"date-time": "",
+		"mac":       "[6]byte",
+	}}, doc)
+	for _, expected := range []string{
+		`uuid "github.com/google/uuid"`,
+		"Id  uuid.UUID `json:\"id\"`",
+		"At  string    `json:\"at,omitempty\"`",
+		"Mac *[6]byte  `json:\"mac,omitempty\"`",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+
+	for _, spec := range []string{"uuid.", ".UUID", "[x]byte", "[16byte", "a/b.1x"} {
+		err := GenerateRoot(&GeneratorOptions{FormatTypes: map[string]string{"uuid": spec}}, jen.NewFile("test"), mustLoadSchemas(t, doc)...)
+		if err == nil || !strings.Contains(err.Error(), "format uuid: invalid Go type") {
+			t.Errorf("%s: expected an invalid Go type, got %v", spec, err)
+		}
+	}
+}
+
+func TestFormatsPackage(t *testing.T) {
+	doc := `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"id": { "type": "string", "format": "uuid" },
+			"day": { "type": "string", "format": "date" },
+			"payload": { "type": "string", "contentEncoding": "base64" }
+		},
+		"required": ["id"]
+	}`
+
+	out := generateString(t, &GeneratorOptions{}, doc)
+	if strings.Contains(out, "formats") {
+		t.Errorf("expected no import of the formats package in\n%s", out)
+	}
+
+	out = generateString(t, &GeneratorOptions{FormatsPackage: true}, doc)
+	for _, expected := range []string{
+		`"github.com/RyoJerryYu/go-jsonschema/formats"`,
+		"Day     *formats.Date `json:\"day,omitempty\"`",
+		"Id      formats.UUID  `json:\"id\"`",
+		"Payload []byte        `json:\"payload,omitempty\"`",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestTypeOverrides(t *testing.T) {
+	doc := `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"price": { "$ref": "https://corp/schemas/money.json" },
+			"total": { "$ref": "#/$defs/total" },
+			"location": { "$ref": "https://corp/schemas/geo.json" },
+			"code": { "$ref": "#/$defs/code" },
+			"tags": { "type": "array", "items": { "type": "string" } }
+		},
+		"required": ["total"],
+		"$defs": {
+			"money": {
+				"$id": "https://corp/schemas/money.json",
+				"type": "object",
+				"properties": { "amount": { "type": "string" } }
+			},
+			"total": { "$ref": "https://corp/schemas/money.json" },
+			"code": { "type": "string" }
+		}
+	}`
+	opts := &GeneratorOptions{
+		StrictRefs: true,
+		TypeOverrides: map[string]string{
+			"https://corp/schemas/money.json":                "github.com/corp/lib/money.Amount",
+			"https://corp/schemas/geo.json":                  "github.com/corp/lib/geo.Point",
+			"https://example.com/root.json#/$defs/code":      "github.com/corp/lib/codes.Code",
+			"https://example.com/root.json#/properties/tags": "[]github.com/corp/lib/tags.Tag",
+		},
+	}
+	out := generateString(t, opts, doc)
+	for _, expected := range []string{
+		"Price    *money.Amount `json:\"price,omitempty\"`",
+		"Total    Total         `json:\"total\"`",
+		"type Total = money.Amount",
+		"Location *geo.Point    `json:\"location,omitempty\"`",
+		"Code     codes.Code    `json:\"code,omitempty\"`",
+		"Tags     []tags.Tag    `json:\"tags,omitempty\"`",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+	for _, unexpected := range []string{"type Money", "type Code"} {
+		if strings.Contains(out, unexpected) {
+			t.Errorf("unexpected %q in\n%s", unexpected, out)
+		}
+	}
+
+	opts.TypeOverrides = map[string]string{"https://corp/schemas/money.json": "money."}
+	err := GenerateRoot(opts, jen.NewFile("test"), mustLoadSchemas(t, doc)...)
+	if err == nil || !strings.Contains(err.Error(), "type override https://corp/schemas/money.json: invalid Go type") {
+		t.Errorf("expected an invalid Go type, got %v", err)
+	}
+}
+
+func TestTypeOverrideExtensions(t *testing.T) {
+	doc := `{
+		"$id": "https://example.com/root.json",
+		"type": "object",
+		"properties": {
+			"pet": {
+				"oneOf": [{ "$ref": "#/$defs/cat" }, { "$ref": "#/$defs/dog" }]
+			}
+		},
+		"$defs": {
+			"cat": {
+				"type": "object",
+				"properties": { "kind": { "const": "cat" } },
+				"required": ["kind"]
+			},
+			"dog": {
+				"type": "object",
+				"properties": { "kind": { "const": "dog" } },
+				"required": ["kind"]
+			}
+		}
+	}`
+	opts := &GeneratorOptions{
+		TypeOverrides: map[string]string{"https://example.com/root.json#/$defs/cat": "github.com/corp/lib/pets.Cat"},
+	}
+	// an existing type can not implement the variant interface
+	out := generateString(t, opts, doc)
+	for _, expected := range []string{
+		"Cat *pets.Cat",
+		"Dog *Dog",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "Variant") {
+		t.Errorf("expected an untagged union in\n%s", out)
+	}
+
+	for _, extension := range []string{
+		`{ "$ref": "#/$defs/cat", "properties": { "name": { "type": "string" } } }`,
+		`{ "allOf": [{ "$ref": "#/$defs/cat" }, { "properties": { "name": { "type": "string" } } }] }`,
+	} {
+		extended := strings.Replace(doc, `"$defs": {`, `"$defs": { "extended": `+extension+`,`, 1)
+		err := GenerateRoot(opts, jen.NewFile("test"), mustLoadSchemas(t, extended)...)
+		if err == nil || !strings.Contains(err.Error(), "can not extend https://example.com/root.json#/$defs/cat, mapped to the Go type github.com/corp/lib/pets.Cat") {
+			t.Errorf("%s: expected an error, got %v", extension, err)
+		}
+	}
+}
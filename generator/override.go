@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+)
+
+// typeOverrides returns the Go types of the TypeOverrides option by the
+// normalized URI of the schemas, and by schema for the indexed ones.
+func typeOverrides(opts *GeneratorOptions, resolver *RefResolver) (map[string]string, map[*jsonschema.Schema]string, error) {
+	byURI := make(map[string]string)
+	bySchema := make(map[*jsonschema.Schema]string)
+	for key, spec := range opts.TypeOverrides {
+		u, err := url.Parse(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("type override %s: %w", key, err)
+		}
+		if _, err := parseGoType(spec); err != nil {
+			return nil, nil, fmt.Errorf("type override %s: %w", key, err)
+		}
+		byURI[u.String()] = spec
+		if schema, ok := resolver.pathToSchema[u.String()]; ok {
+			bySchema[schema] = spec
+		}
+	}
+	return byURI, bySchema, nil
+}
+
+// isOverridden reports whether the schema is mapped to an existing Go type,
+// and not generated.
+func (g *Generator) isOverridden(schema *jsonschema.Schema) bool {
+	_, ok := g.overrides[schema]
+	return ok
+}
+
+// refOverride returns the Go type the $ref of the schema is mapped to,
+// either by the URI it resolves to, which may not be loaded,
+// or by the referred schema, or any schema of the chain of references
+// with the CollapseRefChains option.
+// target is the final target of the reference, nil if not loaded.
+func (g *Generator) refOverride(schema *jsonschema.Schema) (spec string, target *jsonschema.Schema, ok bool) {
+	if schema.Ref == "" {
+		return "", nil, false
+	}
+	chain, err := g.resolver.GetRefChain(schema)
+	if err == nil {
+		target = chain[len(chain)-1]
+		if !g.opts.CollapseRefChains {
+			// the intermediate schemas are aliases of the next one
+			chain = chain[:1]
+		}
+		for _, s := range chain {
+			if spec, ok := g.overrides[s]; ok {
+				return spec, target, true
+			}
+		}
+	}
+	if _, resolved, err := g.resolver.resolve(schema, schema.Ref); err == nil {
+		if spec, ok := g.overrideURIs[resolved.String()]; ok {
+			return spec, target, true
+		}
+	}
+	return "", nil, false
+}
+
+// schemaOverride returns the Go type the schema, or the schema its $ref
+// refers to, is mapped to.
+func (g *Generator) schemaOverride(schema *jsonschema.Schema) (string, bool) {
+	if spec, ok := g.overrides[schema]; ok {
+		return spec, true
+	}
+	spec, _, ok := g.refOverride(schema)
+	return spec, ok
+}
+
+// overrideFieldName names a field after the Go type a schema is mapped to,
+// e.g. "Amount" for "github.com/corp/lib/money.Amount",
+// or "TagList" for "[]github.com/corp/lib/tags.Tag".
+func (g *Generator) overrideFieldName(spec string) string {
+	spec = strings.TrimLeft(spec, "*")
+	suffix := ""
+	if strings.HasPrefix(spec, "[") {
+		spec = strings.TrimLeft(spec[strings.Index(spec, "]")+1:], "*")
+		suffix = "List"
+	}
+	return g.toGolangName(spec[strings.LastIndex(spec, ".")+1:]) + suffix
+}
+
+// generateOverrideType returns the Go type a schema is mapped to.
+// Optional properties are pointers, unless the schema is known to be
+// a scalar or an array, or the type is a slice or a pointer.
+func generateOverrideType(spec string, target *jsonschema.Schema, required bool) jen.Code {
+	// validated by NewGenerator
+	t, _ := parseGoType(spec)
+	if required || strings.HasPrefix(spec, "[]") || strings.HasPrefix(spec, "*") {
+		return t
+	}
+	if target != nil {
+		switch target.SchemaType() {
+		case jsonschema.TypeString, jsonschema.TypeInteger, jsonschema.TypeNumber,
+			jsonschema.TypeBoolean, jsonschema.TypeArray:
+			return t
+		}
+	}
+	return jen.Op("*").Add(t)
+}
@@ -11,10 +11,24 @@ import (
 
 type RefResolver struct {
 	pathToSchema map[string]*jsonschema.Schema
+	// resources maps the URIs of the schema resources to their root schema
+	resources map[string]*jsonschema.Schema
+	// anchors maps the URIs of the $anchor and $dynamicAnchor to their schema
+	anchors map[string]*jsonschema.Schema
+	// dynamicAnchors maps the schema resource URIs to their $dynamicAnchor names
+	dynamicAnchors map[string]map[string]*jsonschema.Schema
+	// locations maps the subschemas to the URI of their schema resource,
+	// with the JSON pointer from the resource as fragment
+	locations map[*jsonschema.Schema]url.URL
+	// paths maps the paths of the schema resource URIs to the URIs having them,
+	// a path resolving to its resource only when no other resource shares it
+	paths map[string][]string
+	// aliases is the paths in pathToSchema mapped as the path of a resource URI
+	aliases map[string]bool
 }
 
 func NewRefResolver(schemas []*jsonschema.Schema) (*RefResolver, error) {
-	r := &RefResolver{pathToSchema: make(map[string]*jsonschema.Schema)}
+	r := newRefResolver()
 	for _, schema := range schemas {
 		err := r.mapPaths(schema)
 		if err != nil {
@@ -24,21 +38,81 @@ func NewRefResolver(schemas []*jsonschema.Schema) (*RefResolver, error) {
 	return r, nil
 }
 
+func newRefResolver() *RefResolver {
+	return &RefResolver{
+		pathToSchema:   make(map[string]*jsonschema.Schema),
+		resources:      make(map[string]*jsonschema.Schema),
+		anchors:        make(map[string]*jsonschema.Schema),
+		dynamicAnchors: make(map[string]map[string]*jsonschema.Schema),
+		locations:      make(map[*jsonschema.Schema]url.URL),
+		paths:          make(map[string][]string),
+		aliases:        make(map[string]bool),
+	}
+}
+
+// DuplicateURIError is returned when two schemas are identified by the same URI,
+// e.g. two files declaring the same $id.
+type DuplicateURIError struct {
+	URI string
+	// Schema is the schema being added, Existing the one already identified by URI
+	Schema   *jsonschema.Schema
+	Existing *jsonschema.Schema
+	// Source and ExistingSource are where the schemas are defined,
+	// their source location, or their URI when not loaded from a file
+	Source         string
+	ExistingSource string
+}
+
+func (e *DuplicateURIError) Error() string {
+	return fmt.Sprintf("duplicate uri %s: defined in %s and %s", e.URI, e.ExistingSource, e.Source)
+}
+
 func (r *RefResolver) insert(uri string, schema *jsonschema.Schema) error {
-	if _, ok := r.pathToSchema[uri]; ok {
-		return fmt.Errorf("attempted to add duplicate uri: %s", uri)
+	if existing, ok := r.pathToSchema[uri]; ok && existing != schema && !r.aliases[uri] {
+		return &DuplicateURIError{
+			URI:            uri,
+			Schema:         schema,
+			Existing:       existing,
+			Source:         r.sourceOf(schema, uri),
+			ExistingSource: r.sourceOf(existing, uri),
+		}
 	}
 	r.pathToSchema[uri] = schema
+	delete(r.aliases, uri)
 	return nil
 }
 
+// insertPath maps the path of the URI of a schema resource to the resource,
+// e.g. "/schemas/a.json" for "https://example.com/schemas/a.json".
+// A path shared by several resources, e.g. the same file under two hosts,
+// is ambiguous and not mapped, the references must use the full URIs.
+// An URI identifying a schema takes precedence over the path of another one.
+func (r *RefResolver) insertPath(path string, uri string, schema *jsonschema.Schema) {
+	r.paths[path] = append(r.paths[path], uri)
+	if _, ok := r.pathToSchema[path]; ok && !r.aliases[path] {
+		return
+	}
+	if len(r.paths[path]) > 1 {
+		delete(r.pathToSchema, path)
+		delete(r.aliases, path)
+		return
+	}
+	r.pathToSchema[path] = schema
+	r.aliases[path] = true
+}
+
 func (r *RefResolver) mapPaths(schema *jsonschema.Schema) error {
 	rootURI := &url.URL{}
 	id := schema.ID
+	if id == "" && schema.Location != nil {
+		// identified by its source, not to collide with the other schemas without $id
+		id = schema.Location.URI
+	}
 	if id == "" {
 		if err := r.insert("#", schema); err != nil {
 			return err
 		}
+		r.resources[""] = schema
 	} else {
 		var err error
 		rootURI, err = url.Parse(id)
@@ -54,17 +128,86 @@ func (r *RefResolver) mapPaths(schema *jsonschema.Schema) error {
 		if err := r.insert(rootURI.String()+"#", schema); err != nil {
 			return err
 		}
-		// add as Path Resolution
-		if err := r.insert(rootURI.Path, schema); err != nil {
+		r.resources[rootURI.String()] = schema
+		// add as Path Resolution,
+		// not for URN or tag URIs, which are opaque identifiers without path
+		if rootURI.Path != "" {
+			r.insertPath(rootURI.Path, rootURI.String(), schema)
+		}
+	}
+	return r.updateURIs(schema, *rootURI, false, false)
+}
+
+// insertAnchors maps the $anchor and $dynamicAnchor of schema
+// as plain name fragments of its schema resource.
+func (r *RefResolver) insertAnchors(schema *jsonschema.Schema, baseURI url.URL) error {
+	resourceURI := baseURI
+	resourceURI.Fragment = ""
+	for _, anchor := range []string{schema.Anchor, schema.DynamicAnchor} {
+		if anchor == "" {
+			continue
+		}
+		anchorURI := resourceURI
+		anchorURI.Fragment = anchor
+		if err := r.insert(anchorURI.String(), schema); err != nil {
 			return err
 		}
+		r.anchors[anchorURI.String()] = schema
+	}
+
+	if schema.DynamicAnchor != "" {
+		anchors, ok := r.dynamicAnchors[resourceURI.String()]
+		if !ok {
+			anchors = make(map[string]*jsonschema.Schema)
+			r.dynamicAnchors[resourceURI.String()] = anchors
+		}
+		anchors[schema.DynamicAnchor] = schema
 	}
-	r.updateURIs(schema, *rootURI, false, false)
 	return nil
 }
 
+// setLocation records the schema resource the schema belongs to.
+// The first one wins, as subschemas of a schema setting a new base URI
+// are mapped under the new base first.
+func (r *RefResolver) setLocation(schema *jsonschema.Schema, baseURI url.URL) {
+	if _, ok := r.locations[schema]; ok {
+		return
+	}
+	r.locations[schema] = baseURI
+}
+
+// ResourceOf returns the URI of the schema resource the schema belongs to,
+// false if the schema was not indexed.
+func (r *RefResolver) ResourceOf(schema *jsonschema.Schema) (string, bool) {
+	uri, ok := r.locations[schema]
+	uri.Fragment = ""
+	return uri.String(), ok
+}
+
+// sourceOf describes where the schema is defined, for error messages.
+func (r *RefResolver) sourceOf(schema *jsonschema.Schema, uri string) string {
+	switch {
+	case schema.Location != nil:
+		return schema.Location.String()
+	case r.uriOf(schema) != "":
+		return r.uriOf(schema)
+	case schema.ID != "":
+		return schema.ID
+	}
+	return uri
+}
+
+// pointerOf returns the JSON pointer of the schema in its schema resource,
+// empty for the resource root or a schema not indexed.
+func (r *RefResolver) pointerOf(schema *jsonschema.Schema) string {
+	return r.locations[schema].Fragment
+}
+
 // create a map of base URIs
 func (r *RefResolver) updateURIs(schema *jsonschema.Schema, baseURI url.URL, checkCurrentID bool, ignoreFragments bool) error {
+	if !checkCurrentID || schema.ID == "" {
+		r.setLocation(schema, baseURI)
+	}
 	// already done for root, and if schema sets a new base URI
 	if checkCurrentID && schema.ID != "" {
 		id := schema.ID
@@ -76,7 +219,7 @@ func (r *RefResolver) updateURIs(schema *jsonschema.Schema, baseURI url.URL, che
 		// ignore the fragment, since it won't be resolvable under the current baseURI.
 		if !(strings.HasPrefix(id, "#") && ignoreFragments) {
 			// map all the subschema under the new base
-			resolved := baseURI.ResolveReference(newBase)
+			resolved := resolveReference(&baseURI, newBase)
 			if err := r.insert(resolved.String(), schema); err != nil {
 				return err
 			}
@@ -84,6 +227,7 @@ func (r *RefResolver) updateURIs(schema *jsonschema.Schema, baseURI url.URL, che
 				if err := r.insert(resolved.String()+"#", schema); err != nil {
 					return err
 				}
+				r.resources[resolved.String()] = schema
 			}
 			if err := r.updateURIs(schema, *resolved, false, false); err != nil {
 				return err
@@ -92,49 +236,199 @@ func (r *RefResolver) updateURIs(schema *jsonschema.Schema, baseURI url.URL, che
 			ignoreFragments = true
 		}
 	}
-	for k, subSchema := range schema.Defs {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/$defs/" + k
-		if err := r.insert(newBaseURI.String(), subSchema); err != nil {
+	// anchors only belong to the schema resource they are defined in
+	if !ignoreFragments {
+		if err := r.insertAnchors(schema, baseURI); err != nil {
 			return err
 		}
-		r.updateURIs(subSchema, newBaseURI, true, ignoreFragments)
 	}
-	for k, subSchema := range schema.Properties {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/properties/" + k
-		if err := r.insert(newBaseURI.String(), subSchema); err != nil {
-			return err
+	var err error
+	schema.Subschemas(func(path []string, subSchema *jsonschema.Schema) {
+		if err != nil {
+			return
 		}
-		r.updateURIs(subSchema, newBaseURI, true, ignoreFragments)
-	}
-	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsSchema() {
 		newBaseURI := baseURI
-		newBaseURI.Fragment += "/additionalProperties"
-		r.updateURIs(schema.AdditionalProperties.Schema, newBaseURI, true, ignoreFragments)
+		for _, token := range path {
+			newBaseURI.Fragment += "/" + escapePointerToken(token)
+		}
+		if err = r.insert(newBaseURI.String(), subSchema); err != nil {
+			return
+		}
+		err = r.updateURIs(subSchema, newBaseURI, true, ignoreFragments)
+	})
+	return err
+}
+
+// escapePointerToken escapes a JSON pointer reference token.
+func escapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+// UnresolvedRefError is returned when a reference is not found.
+type UnresolvedRefError struct {
+	// Schema is the referring schema
+	Schema *jsonschema.Schema
+	// From is the URI of the referring schema, with its JSON pointer as fragment
+	From string
+	// Ref is the reference as written in the schema
+	Ref string
+	// URI is the absolute URI the reference was resolved to
+	URI string
+	// Ambiguous is the URIs of the schema resources sharing the path URI
+	// resolves to, when more than one
+	Ambiguous []string
+}
+
+func (e *UnresolvedRefError) Error() string {
+	msg := fmt.Sprintf("reference not found: %s (resolved as %s)", e.Ref, e.URI)
+	if len(e.Ambiguous) > 1 {
+		msg = fmt.Sprintf("ambiguous reference: %s (resolved as %s, the path of %s)", e.Ref, e.URI, strings.Join(e.Ambiguous, ", "))
 	}
-	if schema.Items != nil {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/items"
-		r.updateURIs(schema.Items, newBaseURI, true, ignoreFragments)
+	switch {
+	case e.Schema != nil && e.Schema.Location != nil:
+		msg = e.Schema.Location.String() + ": " + msg
+	case e.From != "":
+		msg = e.From + ": " + msg
 	}
-	return nil
+	return msg
 }
 
-// GetSchemaByReference returns the schema.
+// GetSchemaByReference returns the schema the $ref of schema refers to.
+// The reference is resolved against the base URI of the schema resource
+// enclosing schema, i.e. the nearest $id, or the URI of the file.
 func (r *RefResolver) GetSchemaByReference(schema *jsonschema.Schema) (*jsonschema.Schema, error) {
-	u, err := url.Parse(schema.ID)
+	target, resolved, err := r.resolve(schema, schema.Ref)
 	if err != nil {
 		return nil, err
 	}
-	ref, err := url.Parse(schema.Ref)
+	if target == nil {
+		return nil, r.unresolved(schema, schema.Ref, resolved)
+	}
+	return target, nil
+}
+
+// RefCycleError is returned when a chain of pure $ref schemas is cyclic.
+type RefCycleError struct {
+	// Schema is the referring schema
+	Schema *jsonschema.Schema
+	// Chain is the URIs of the schemas in the cycle,
+	// starting and ending with the same one
+	Chain []string
+}
+
+func (e *RefCycleError) Error() string {
+	msg := "cyclic references: " + strings.Join(e.Chain, " -> ")
+	if e.Schema != nil && e.Schema.Location != nil {
+		msg = e.Schema.Location.String() + ": " + msg
+	}
+	return msg
+}
+
+// GetRefChain follows the $ref of schema through the schemas which are
+// only a $ref, and returns the referred schemas in order.
+// The last one is the final target, which is not a pure $ref.
+// Returns a *RefCycleError if the chain is cyclic.
+func (r *RefResolver) GetRefChain(schema *jsonschema.Schema) ([]*jsonschema.Schema, error) {
+	seen := map[*jsonschema.Schema]int{}
+	var chain []*jsonschema.Schema
+	current := schema
+	for {
+		target, err := r.GetSchemaByReference(current)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := seen[target]; ok {
+			var uris []string
+			for _, s := range chain[i:] {
+				uris = append(uris, r.uriOf(s))
+			}
+			uris = append(uris, r.uriOf(target))
+			return nil, &RefCycleError{Schema: schema, Chain: uris}
+		}
+		seen[target] = len(chain)
+		chain = append(chain, target)
+		if !target.IsPureRef() {
+			return chain, nil
+		}
+		current = target
+	}
+}
+
+// uriOf returns the URI of the schema, with its JSON pointer as fragment.
+func (r *RefResolver) uriOf(schema *jsonschema.Schema) string {
+	if u, ok := r.locations[schema]; ok {
+		return u.String()
+	}
+	return schema.ID
+}
+
+// GetSchemaByDynamicReference returns the schema the $dynamicRef of schema
+// refers to, in the dynamic scope.
+//
+// The dynamic scope is the list of the schema resource URIs entered
+// from the outermost one. When the $dynamicRef statically resolves to a
+// $dynamicAnchor, the outermost resource in scope declaring the same
+// $dynamicAnchor is used instead. Otherwise it behaves like $ref.
+func (r *RefResolver) GetSchemaByDynamicReference(schema *jsonschema.Schema, scope []string) (*jsonschema.Schema, error) {
+	target, resolved, err := r.resolve(schema, schema.DynamicRef)
 	if err != nil {
 		return nil, err
 	}
-	resolvedPath := u.ResolveReference(ref)
-	path, ok := r.pathToSchema[resolvedPath.String()]
+	if target == nil {
+		return nil, r.unresolved(schema, schema.DynamicRef, resolved)
+	}
+
+	// only a plain name fragment of a $dynamicAnchor is dynamic
+	name := resolved.Fragment
+	if name == "" || strings.HasPrefix(name, "/") || target.DynamicAnchor != name {
+		return target, nil
+	}
+	for _, resource := range scope {
+		if anchored, ok := r.dynamicAnchors[resource][name]; ok {
+			return anchored, nil
+		}
+	}
+	return target, nil
+}
+
+func (r *RefResolver) unresolved(schema *jsonschema.Schema, ref string, resolved *url.URL) *UnresolvedRefError {
+	e := &UnresolvedRefError{Schema: schema, Ref: ref, URI: resolved.String()}
+	if uris := r.paths[resolved.String()]; len(uris) > 1 {
+		e.Ambiguous = uris
+	}
+	if _, ok := r.locations[schema]; ok {
+		e.From = r.uriOf(schema)
+	}
+	return e
+}
+
+// resolve looks up the reference against the schema resource of schema,
+// or its own $id if schema was not indexed.
+// The returned schema is nil if the reference is not found.
+func (r *RefResolver) resolve(schema *jsonschema.Schema, ref string) (*jsonschema.Schema, *url.URL, error) {
+	base, ok := r.ResourceOf(schema)
 	if !ok {
-		return nil, errors.New("refresolver.GetSchemaByReference: reference not found: " + schema.Ref)
+		base = schema.ID
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	refURI, err := url.Parse(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved := resolveReference(u, refURI)
+	return r.pathToSchema[resolved.String()], resolved, nil
+}
+
+// resolveReference resolves ref against base.
+// A base with an opaque URI, such as a URN, only resolves fragments,
+// other relative references are kept as is.
+func resolveReference(base *url.URL, ref *url.URL) *url.URL {
+	if base.Opaque != "" && !ref.IsAbs() && (ref.Path != "" || ref.Host != "" || ref.RawQuery != "") {
+		return ref
 	}
-	return path, nil
+	return base.ResolveReference(ref)
 }
@@ -0,0 +1,411 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+)
+
+func mustLoadSchemas(t *testing.T, docs ...string) []*jsonschema.Schema {
+	t.Helper()
+	var schemas []*jsonschema.Schema
+	for _, doc := range docs {
+		schema := &jsonschema.Schema{}
+		if err := json.Unmarshal([]byte(doc), schema); err != nil {
+			t.Fatal(err)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+func TestResolveAnchors(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{
+			"$id": "https://example.com/root.json",
+			"properties": {
+				"local": { "$ref": "#address" },
+				"remote": { "$ref": "other.json#node" },
+				"dynamic": { "$ref": "other.json#tree" }
+			},
+			"$defs": {
+				"address": { "$anchor": "address", "title": "address" }
+			}
+		}`,
+		`{
+			"$id": "https://example.com/other.json",
+			"$defs": {
+				"node": { "$anchor": "node", "title": "node" },
+				"tree": { "$dynamicAnchor": "tree", "title": "tree" },
+				"nested": {
+					"$id": "nested.json",
+					"$defs": {
+						"inner": { "$anchor": "inner", "title": "inner" }
+					}
+				}
+			}
+		}`,
+	)
+
+	r, err := NewRefResolver(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ref      string
+		expected string
+	}{
+		{"#address", "address"},
+		{"other.json#node", "node"},
+		{"other.json#tree", "tree"},
+		{"nested.json#inner", "inner"},
+	}
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			ref := &jsonschema.Schema{ID: schemas[0].ID, Ref: c.ref}
+			schema, err := r.GetSchemaByReference(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schema.Title != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, schema.Title)
+			}
+		})
+	}
+
+	// anchors do not leak out of their schema resource
+	ref := &jsonschema.Schema{ID: schemas[0].ID, Ref: "other.json#inner"}
+	if _, err := r.GetSchemaByReference(ref); err == nil {
+		t.Error("expected other.json#inner not to be resolved")
+	}
+}
+
+func TestResolveDynamicRef(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{
+			"$id": "https://example.com/tree",
+			"$dynamicAnchor": "node",
+			"title": "tree",
+			"properties": {
+				"children": { "items": { "$dynamicRef": "#node" } },
+				"static": { "$dynamicRef": "#/properties/children" }
+			}
+		}`,
+		`{
+			"$id": "https://example.com/labeled-tree",
+			"$dynamicAnchor": "node",
+			"$ref": "tree",
+			"title": "labeled-tree"
+		}`,
+	)
+
+	r, err := NewRefResolver(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := schemas[0].Properties["children"].Items
+	cases := []struct {
+		name     string
+		schema   *jsonschema.Schema
+		scope    []string
+		expected string
+	}{
+		{
+			name:     "in the base resource",
+			schema:   items,
+			scope:    []string{"https://example.com/tree"},
+			expected: "tree",
+		},
+		{
+			name:     "in the extension",
+			schema:   items,
+			scope:    []string{"https://example.com/labeled-tree", "https://example.com/tree"},
+			expected: "labeled-tree",
+		},
+		{
+			name:     "json pointer is static",
+			schema:   schemas[0].Properties["static"],
+			scope:    []string{"https://example.com/labeled-tree", "https://example.com/tree"},
+			expected: "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schema, err := r.GetSchemaByDynamicReference(c.schema, c.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schema.Title != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, schema.Title)
+			}
+		})
+	}
+}
+
+func TestResolveEverySubschemaLocation(t *testing.T) {
+	schemas := mustLoadSchemas(t, `{
+		"$id": "https://example.com/root.json",
+		"allOf": [{ "title": "allOf0" }],
+		"oneOf": [{ "title": "oneOf0" }, { "properties": { "x": { "title": "oneOf1x" } } }],
+		"anyOf": [{ "title": "anyOf0" }],
+		"not": { "title": "not" },
+		"if": { "title": "if" },
+		"then": { "title": "then" },
+		"else": { "title": "else" },
+		"dependentSchemas": { "foo": { "title": "foo" } },
+		"prefixItems": [{ "title": "p0" }, { "title": "p1" }, { "title": "p2" }],
+		"items": false,
+		"contains": { "title": "contains" },
+		"patternProperties": { "^x-": { "title": "x-" } },
+		"additionalProperties": { "title": "additionalProperties" },
+		"propertyNames": { "title": "propertyNames" },
+		"unevaluatedProperties": { "title": "unevaluatedProperties" },
+		"$defs": { "a/b": { "title": "a/b" } }
+	}`)
+
+	r, err := NewRefResolver(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ref      string
+		expected string
+	}{
+		{"#/allOf/0", "allOf0"},
+		{"#/oneOf/1/properties/x", "oneOf1x"},
+		{"#/anyOf/0", "anyOf0"},
+		{"#/not", "not"},
+		{"#/if", "if"},
+		{"#/then", "then"},
+		{"#/else", "else"},
+		{"#/dependentSchemas/foo", "foo"},
+		{"#/prefixItems/2", "p2"},
+		{"#/contains", "contains"},
+		{"#/patternProperties/^x-", "x-"},
+		{"#/patternProperties/%5Ex-", "x-"},
+		{"#/additionalProperties", "additionalProperties"},
+		{"#/propertyNames", "propertyNames"},
+		{"#/unevaluatedProperties", "unevaluatedProperties"},
+		{"#/$defs/a~1b", "a/b"},
+	}
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			ref := &jsonschema.Schema{ID: schemas[0].ID, Ref: c.ref}
+			schema, err := r.GetSchemaByReference(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schema.Title != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, schema.Title)
+			}
+		})
+	}
+
+	ref := &jsonschema.Schema{ID: schemas[0].ID, Ref: "#/items"}
+	schema, err := r.GetSchemaByReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Boolean == nil || *schema.Boolean {
+		t.Errorf("expected the false schema, got %+v", schema)
+	}
+}
+
+func TestResolveAgainstEnclosingResource(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{
+			"$id": "https://example.com/schema/a.json",
+			"properties": {
+				"sibling": { "$ref": "b.json#/$defs/x" },
+				"local": { "items": { "$ref": "#/$defs/y" } },
+				"nested": {
+					"$id": "nested/c.json",
+					"properties": {
+						"relative": { "$ref": "d.json" },
+						"local": { "$ref": "#/$defs/z" }
+					},
+					"$defs": { "z": { "title": "nested z" } }
+				}
+			},
+			"$defs": { "y": { "title": "a y" } }
+		}`,
+		`{
+			"$id": "https://example.com/schema/b.json",
+			"$defs": { "x": { "title": "b x" } }
+		}`,
+		`{
+			"$id": "https://example.com/schema/nested/d.json",
+			"title": "d"
+		}`,
+	)
+
+	r, err := NewRefResolver(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := schemas[0].Properties
+	cases := []struct {
+		name     string
+		schema   *jsonschema.Schema
+		expected string
+	}{
+		{"sibling file", props["sibling"], "b x"},
+		{"same file", props["local"].Items, "a y"},
+		{"relative to nested $id", props["nested"].Properties["relative"], "d"},
+		{"fragment of nested $id", props["nested"].Properties["local"], "nested z"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schema, err := r.GetSchemaByReference(c.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if schema.Title != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, schema.Title)
+			}
+		})
+	}
+}
+
+func TestResolveURN(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{
+			"$id": "urn:corp:event:order-created:v1",
+			"properties": {
+				"local": { "$ref": "#/$defs/line" },
+				"anchor": { "$ref": "#total" },
+				"other": { "$ref": "urn:corp:event:order-cancelled:v1#/$defs/reason" },
+				"relative": { "$ref": "sibling.json" }
+			},
+			"$defs": {
+				"line": { "title": "line" },
+				"total": { "$anchor": "total", "title": "total" }
+			}
+		}`,
+		`{
+			"$id": "urn:corp:event:order-cancelled:v1",
+			"$defs": {
+				"reason": { "title": "reason" }
+			}
+		}`,
+	)
+
+	r, err := NewRefResolver(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	props := schemas[0].Properties
+	for name, expected := range map[string]string{
+		"local":  "line",
+		"anchor": "total",
+		"other":  "reason",
+	} {
+		schema, err := r.GetSchemaByReference(props[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if schema.Title != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, schema.Title)
+		}
+	}
+
+	_, err = r.GetSchemaByReference(props["relative"])
+	var unresolved *UnresolvedRefError
+	if !errors.As(err, &unresolved) || unresolved.URI != "sibling.json" {
+		t.Errorf("expected sibling.json to be unresolved against a URN, got %v", err)
+	}
+}
+
+func TestDuplicateURI(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{ "$id": "https://example.com/a.json", "title": "a" }`,
+		`{ "$id": "https://example.com/a.json", "title": "vendored a" }`,
+	)
+	schemas[0].Location = &jsonschema.Location{File: "a.json", Start: jsonschema.Position{Line: 1, Column: 1}}
+	schemas[1].Location = &jsonschema.Location{File: "vendor/a.json", Start: jsonschema.Position{Line: 1, Column: 1}}
+
+	_, err := NewRefResolver(schemas)
+	var duplicate *DuplicateURIError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("expected a duplicate uri error, got %v", err)
+	}
+	expected := "duplicate uri https://example.com/a.json: defined in a.json:1:1 and vendor/a.json:1:1"
+	if duplicate.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, duplicate.Error())
+	}
+}
+
+func TestAmbiguousPath(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{ "$id": "https://a.example.com/schemas/item.json", "title": "a" }`,
+		`{ "$id": "https://b.example.com/schemas/item.json", "title": "b" }`,
+		`{ "$id": "https://example.com/schemas/order.json", "title": "order" }`,
+		`{ "properties": {
+			"item": { "$ref": "/schemas/item.json" },
+			"order": { "$ref": "/schemas/order.json" }
+		} }`,
+	)
+
+	resolver, err := NewRefResolver(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ uri, expected string }{
+		{"https://a.example.com/schemas/item.json", "a"},
+		{"https://b.example.com/schemas/item.json", "b"},
+	} {
+		if schema, ok := resolver.pathToSchema[c.uri]; !ok || schema.Title != c.expected {
+			t.Errorf("expected %s to resolve to %s", c.uri, c.expected)
+		}
+	}
+	order, err := resolver.GetSchemaByReference(schemas[3].Properties["order"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Title != "order" {
+		t.Errorf("expected order, got %s", order.Title)
+	}
+
+	_, err = resolver.GetSchemaByReference(schemas[3].Properties["item"])
+	var unresolved *UnresolvedRefError
+	if !errors.As(err, &unresolved) {
+		t.Fatalf("expected an unresolved reference error, got %v", err)
+	}
+	expected := "#/properties/item: ambiguous reference: /schemas/item.json (resolved as /schemas/item.json, the path of https://a.example.com/schemas/item.json, https://b.example.com/schemas/item.json)"
+	if unresolved.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, unresolved.Error())
+	}
+}
+
+func TestResolveSourceWithoutID(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{ "$defs": { "a": { "title": "a" } } }`,
+		`{ "$defs": { "b": { "$ref": "a.json#/$defs/a" } } }`,
+	)
+	schemas[0].Location = &jsonschema.Location{URI: "file:///schemas/a.json", File: "a.json"}
+	schemas[1].Location = &jsonschema.Location{URI: "file:///schemas/b.json", File: "b.json"}
+
+	registry, err := NewRegistry(schemas...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"file:///schemas/a.json", "file:///schemas/b.json"}
+	if resources := registry.Resources(); !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected resources %v, got %v", expected, resources)
+	}
+	target, err := registry.Resolver().GetSchemaByReference(schemas[1].Defs["b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Title != "a" {
+		t.Errorf("expected a, got %s", target.Title)
+	}
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/go-errors/errors"
+)
+
+// Registry is the index of the schema resources, their subschemas and
+// their anchors by URI, resolving them the same way as the generator.
+type Registry struct {
+	resolver *RefResolver
+}
+
+func NewRegistry(schemas ...*jsonschema.Schema) (*Registry, error) {
+	registry := &Registry{resolver: newRefResolver()}
+	if err := registry.Add(schemas...); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Add indexes the schemas as schema resources, with their subschemas,
+// embedded schema resources and anchors.
+func (r *Registry) Add(schemas ...*jsonschema.Schema) error {
+	for _, schema := range schemas {
+		if err := r.resolver.mapPaths(schema); err != nil {
+			return errors.New(err)
+		}
+	}
+	return nil
+}
+
+// Lookup returns the schema identified by the absolute URI,
+// whose fragment may be a JSON pointer or an anchor name.
+func (r *Registry) Lookup(uri string) (*jsonschema.Schema, bool) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, false
+	}
+	schema, ok := r.resolver.pathToSchema[u.String()]
+	return schema, ok
+}
+
+// LookupPointer returns the schema at the JSON pointer in the schema
+// resource identified by the absolute URI.
+func (r *Registry) LookupPointer(uri string, pointer string) (*jsonschema.Schema, bool) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, false
+	}
+	u.Fragment = pointer
+	return r.Lookup(u.String())
+}
+
+// Resources returns the sorted URIs of all the schema resources,
+// including the ones embedded with $id.
+// A schema without $id is identified by the URI of its source,
+// and without source either, is the resource with an empty URI.
+func (r *Registry) Resources() []string {
+	return sortedKeys(r.resolver.resources)
+}
+
+// Anchors returns the sorted URIs of all the $anchor and $dynamicAnchor,
+// the anchor name being the fragment of the URI of their schema resource.
+func (r *Registry) Anchors() []string {
+	return sortedKeys(r.resolver.anchors)
+}
+
+// URIOf returns the canonical URI of the schema, i.e. the URI of its
+// schema resource with the JSON pointer from the resource as fragment,
+// false if the schema was not indexed.
+func (r *Registry) URIOf(schema *jsonschema.Schema) (string, bool) {
+	if _, ok := r.resolver.locations[schema]; !ok {
+		return "", false
+	}
+	return r.resolver.uriOf(schema), true
+}
+
+// Resolver returns the reference resolver backed by the registry.
+func (r *Registry) Resolver() *RefResolver {
+	return r.resolver
+}
+
+func sortedKeys(m map[string]*jsonschema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
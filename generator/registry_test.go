@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	schemas := mustLoadSchemas(t,
+		`{
+			"$id": "https://example.com/a.json",
+			"properties": {
+				"name": { "$anchor": "name", "title": "name" },
+				"nested": {
+					"$id": "nested.json",
+					"$dynamicAnchor": "node",
+					"title": "nested"
+				}
+			}
+		}`,
+		`{
+			"$id": "urn:example:b",
+			"$defs": { "x/y": { "title": "x/y" } }
+		}`,
+	)
+
+	registry, err := NewRegistry(schemas[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(schemas[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedResources := []string{
+		"https://example.com/a.json",
+		"https://example.com/nested.json",
+		"urn:example:b",
+	}
+	if resources := registry.Resources(); !reflect.DeepEqual(resources, expectedResources) {
+		t.Errorf("expected resources %v, got %v", expectedResources, resources)
+	}
+	expectedAnchors := []string{
+		"https://example.com/a.json#name",
+		"https://example.com/nested.json#node",
+	}
+	if anchors := registry.Anchors(); !reflect.DeepEqual(anchors, expectedAnchors) {
+		t.Errorf("expected anchors %v, got %v", expectedAnchors, anchors)
+	}
+
+	cases := []struct {
+		uri      string
+		pointer  string
+		expected string
+	}{
+		{"https://example.com/a.json#name", "", "name"},
+		{"https://example.com/a.json", "/properties/nested", "nested"},
+		{"https://example.com/nested.json", "", "nested"},
+		{"urn:example:b", "/$defs/x~1y", "x/y"},
+	}
+	for _, c := range cases {
+		t.Run(c.uri+c.pointer, func(t *testing.T) {
+			schema, ok := registry.LookupPointer(c.uri, c.pointer)
+			if c.pointer == "" {
+				schema, ok = registry.Lookup(c.uri)
+			}
+			if !ok {
+				t.Fatal("expected a schema")
+			}
+			if schema.Title != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, schema.Title)
+			}
+		})
+	}
+
+	if _, ok := registry.Lookup("https://example.com/missing.json"); ok {
+		t.Error("expected missing.json not to be found")
+	}
+
+	for schema, expected := range map[string]string{
+		"name":   "https://example.com/a.json#/properties/name",
+		"nested": "https://example.com/nested.json",
+	} {
+		uri, ok := registry.URIOf(schemas[0].Properties[schema])
+		if !ok || uri != expected {
+			t.Errorf("expected the canonical uri %s, got %s", expected, uri)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"strconv"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+)
+
+// isTuple reports whether the schema is an array with prefixItems,
+// generated as a struct with a field per item.
+func isTuple(schema *jsonschema.Schema) bool {
+	return schema.Ref == "" && len(schema.PrefixItems) > 0 &&
+		(len(schema.Type) == 0 || schema.SchemaType() == jsonschema.TypeArray)
+}
+
+// tupleField is a field of a tuple struct.
+type tupleField struct {
+	name string
+	t    jen.Code
+	// optional is for the items after minItems, which may be absent
+	optional bool
+}
+
+// tupleFields returns the fields of the prefixItems of the tuple,
+// named after the title of the items, or V0, V1... otherwise.
+func (g *Generator) tupleFields(schema *jsonschema.Schema) []tupleField {
+	taken := map[string]bool{"Rest": true}
+	fields := make([]tupleField, len(schema.PrefixItems))
+	for i := range schema.PrefixItems {
+		item := &schema.PrefixItems[i]
+		name := g.toGolangName(item.Title)
+		if name == "" || taken[name] || name[0] == '_' {
+			name = "V" + strconv.Itoa(i)
+		}
+		taken[name] = true
+
+		optional := i >= schema.MinItems
+		t := g.generateSchemaType(item, true)
+		if _, nullable := item.UnwrapNullableSchema(); optional && !nullable && item.SchemaType() != jsonschema.TypeArray {
+			// a pointer to tell an absent item
+			t = jen.Op("*").Add(t)
+		}
+		fields[i] = tupleField{name: name, t: t, optional: optional}
+	}
+	return fields
+}
+
+// generateTuple generates the array as a struct with a field per prefix item,
+// and the trailing items in the Rest slice, e.g.
+//
+//	type Point struct {
+//		X    int64
+//		Y    int64
+//		Rest []string
+//	}
+//
+// with MarshalJSON and UnmarshalJSON to and from a JSON array.
+// There is no Rest field when items is false, extra items failing the decoding.
+// Without items, the extra items are kept as raw JSON values.
+func (g *Generator) generateTuple(schema *jsonschema.Schema, id string, file *jen.File) {
+	fields := g.tupleFields(schema)
+	forbidExtra := schema.Items != nil && schema.Items.Boolean != nil && !*schema.Items.Boolean
+	var restT jen.Code = jen.Qual("encoding/json", "RawMessage")
+	if schema.Items != nil && schema.Items.Boolean == nil {
+		restT = g.generateSchemaType(schema.Items, true)
+	}
+
+	var defs []jen.Code
+	for i, field := range fields {
+		def := jen.Id(field.name).Add(field.t)
+		if description := schema.PrefixItems[i].Description; description != "" {
+			def.Comment(description)
+		}
+		defs = append(defs, def)
+	}
+	if !forbidExtra {
+		defs = append(defs, jen.Id("Rest").Index().Add(restT).Comment("the items after the prefix items"))
+	}
+	file.Type().Id(id).Struct(defs...).Line()
+
+	// MarshalJSON
+	required := len(fields)
+	if schema.MinItems < required {
+		required = schema.MinItems
+	}
+	var values []jen.Code
+	for _, field := range fields {
+		values = append(values, jen.Id("v").Dot(field.name))
+	}
+	var marshal []jen.Code
+	if required < len(fields) {
+		// the trailing unset optional items are omitted
+		marshal = append(marshal, jen.Id("n").Op(":=").Lit(required))
+		for i, field := range fields[required:] {
+			marshal = append(marshal, jen.If(jen.Id("v").Dot(field.name).Op("!=").Nil()).Block(
+				jen.Id("n").Op("=").Lit(required+i+1),
+			))
+		}
+		if !forbidExtra {
+			marshal = append(marshal, jen.If(jen.Len(jen.Id("v").Dot("Rest")).Op(">").Lit(0)).Block(
+				jen.Id("n").Op("=").Lit(len(fields)),
+			))
+		}
+		marshal = append(marshal, jen.Id("items").Op(":=").Index().Interface().Values(values...).Index(jen.Empty(), jen.Id("n")))
+	} else {
+		marshal = append(marshal, jen.Id("items").Op(":=").Index().Interface().Values(values...))
+	}
+	if !forbidExtra {
+		marshal = append(marshal, jen.For(jen.List(jen.Id("_"), jen.Id("item")).Op(":=").Range().Id("v").Dot("Rest")).Block(
+			jen.Id("items").Op("=").Append(jen.Id("items"), jen.Id("item")),
+		))
+	}
+	marshal = append(marshal, jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("items"))))
+
+	file.Commentf("MarshalJSON encodes %s as a JSON array.", id)
+	file.Func().Params(jen.Id("v").Id(id)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(marshal...).Line()
+
+	// UnmarshalJSON
+	decodeItem := func(item jen.Code, target jen.Code, errorf ...jen.Code) jen.Code {
+		return jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(item, target),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(append(errorf, jen.Err())...)),
+		)
+	}
+	unmarshal := []jen.Code{
+		jen.Var().Id("items").Index().Qual("encoding/json", "RawMessage"),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("items")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())),
+	}
+	if required > 0 {
+		unmarshal = append(unmarshal, jen.If(jen.Len(jen.Id("items")).Op("<").Lit(required)).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(id+" needs at least "+countItems(required)+", got %d"), jen.Len(jen.Id("items")))),
+		))
+	}
+	if forbidExtra {
+		unmarshal = append(unmarshal, jen.If(jen.Len(jen.Id("items")).Op(">").Lit(len(fields))).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(id+" has at most "+countItems(len(fields))+", got %d"), jen.Len(jen.Id("items")))),
+		))
+	}
+	unmarshal = append(unmarshal, jen.Op("*").Id("v").Op("=").Id(id).Values())
+	for i, field := range fields {
+		decode := decodeItem(jen.Id("items").Index(jen.Lit(i)), jen.Op("&").Id("v").Dot(field.name),
+			jen.Lit("item "+strconv.Itoa(i)+" of "+id+": %w"))
+		if field.optional {
+			decode = jen.If(jen.Len(jen.Id("items")).Op(">").Lit(i)).Block(decode)
+		}
+		unmarshal = append(unmarshal, decode)
+	}
+	if !forbidExtra {
+		unmarshal = append(unmarshal, jen.If(jen.Len(jen.Id("items")).Op(">").Lit(len(fields))).Block(
+			jen.Id("v").Dot("Rest").Op("=").Make(jen.Index().Add(restT), jen.Len(jen.Id("items")).Op("-").Lit(len(fields))),
+			jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("items").Index(jen.Lit(len(fields)), jen.Empty())).Block(
+				decodeItem(jen.Id("item"), jen.Op("&").Id("v").Dot("Rest").Index(jen.Id("i")),
+					jen.Lit("item %d of "+id+": %w"), jen.Lit(len(fields)).Op("+").Id("i")),
+			),
+		))
+	}
+	unmarshal = append(unmarshal, jen.Return(jen.Nil()))
+
+	file.Commentf("UnmarshalJSON decodes %s from a JSON array.", id)
+	file.Func().Params(jen.Id("v").Op("*").Id(id)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(unmarshal...).Line()
+}
+
+// countItems returns "1 item" or "<n> items".
+func countItems(n int) string {
+	if n == 1 {
+		return "1 item"
+	}
+	return strconv.Itoa(n) + " items"
+}
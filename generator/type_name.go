@@ -13,6 +13,12 @@ import (
 
 func (g *Generator) SchemaTypeName(schema *jsonschema.Schema) string {
 	name := getIdentifier(schema)
+	if schema.Title == "" && schema.ID == "" && g.resolver != nil {
+		// a subschema is named by its location in the enclosing schema resource
+		if pointer := g.resolver.pointerOf(schema); pointer != "" {
+			name = getPointerIdentifier(pointer)
+		}
+	}
 	return g.toGolangName(name)
 }
 
@@ -87,8 +93,12 @@ func getIdentifier(schema *jsonschema.Schema) string {
 		return schema.Title
 	}
 
+	if u := mustURIFromSchema(schema); u.Opaque != "" {
+		return getOpaqueIdentifier(u.Opaque)
+	}
+
 	uri := mustPathFromSchema(schema)
-	name := strings.TrimSuffix(filepath.Base(uri), ".json") // filename without extension
+	name := trimSchemaExt(filepath.Base(uri)) // filename without extension
 	dir := filepath.Dir(uri)
 
 MATCH_LOOP:
@@ -115,13 +125,61 @@ MATCH_LOOP:
 	}
 }
 
-func mustPathFromSchema(schema *jsonschema.Schema) string {
+// trimSchemaExt removes the extension of JSON, JSONC and JSON5 files.
+func trimSchemaExt(name string) string {
+	for _, ext := range []string{".json", ".jsonc", ".json5"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// getPointerIdentifier names a subschema after the last token of its
+// JSON pointer, e.g. "address" for "/$defs/address",
+// or the last two tokens for an index, e.g. "allOf_0" for "/allOf/0".
+func getPointerIdentifier(pointer string) string {
+	tokens := strings.Split(pointer, "/")
+	name := tokens[len(tokens)-1]
+	if strings.IndexAny(name, "0123456789") == 0 && len(tokens) > 1 {
+		name = tokens[len(tokens)-2] + "_" + name
+	}
+	name = strings.ReplaceAll(name, "~1", "/")
+	return strings.ReplaceAll(name, "~0", "~")
+}
+
+var versionPattern = regexp.MustCompile(`^[vV]?[0-9]+([._\-][0-9]+)*$`)
+
+// getOpaqueIdentifier names a schema identified by an opaque URI,
+// such as "urn:corp:event:order-created:v1" or "tag:corp.com,2024:schemas/order",
+// after its last segment, with the previous one if the last is a version,
+// e.g. "order_created_v1" and "order".
+func getOpaqueIdentifier(opaque string) string {
+	segments := strings.FieldsFunc(opaque, func(c rune) bool {
+		return c == ':' || c == '/'
+	})
+	if len(segments) == 0 {
+		return "root"
+	}
+	name := segments[len(segments)-1]
+	if len(segments) > 1 && versionPattern.MatchString(name) {
+		name = segments[len(segments)-2] + "_" + name
+	}
+	name = trimSchemaExt(name)
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func mustURIFromSchema(schema *jsonschema.Schema) *url.URL {
 	u, err := url.Parse(schema.ID)
 	if err != nil {
 		panic(err)
 	}
 
-	return u.Path
+	return u
+}
+
+func mustPathFromSchema(schema *jsonschema.Schema) string {
+	return mustURIFromSchema(schema).Path
 }
 
 func baseOrRoot(path string) string {
@@ -111,6 +111,12 @@ func TestFormatRootStructName(t *testing.T) {
 			title:    "",
 			expected: "id",
 		},
+		{
+			name:     "id with json5 extension",
+			schemaID: "id.json5",
+			title:    "",
+			expected: "id",
+		},
 		{
 			name:     "id with directory",
 			schemaID: "dir/id",
@@ -135,6 +141,24 @@ func TestFormatRootStructName(t *testing.T) {
 			title:    "",
 			expected: "contain_dash",
 		},
+		{
+			name:     "urn",
+			schemaID: "urn:corp:event:order-created:v1",
+			title:    "",
+			expected: "order_created_v1",
+		},
+		{
+			name:     "urn with fragment",
+			schemaID: "urn:corp:event:order#/$defs/x",
+			title:    "",
+			expected: "order",
+		},
+		{
+			name:     "tag uri",
+			schemaID: "tag:corp.com,2024:schemas/order.json",
+			title:    "",
+			expected: "order",
+		},
 		{
 			name:     "contain underscore",
 			schemaID: "dir/$id/index.json",
@@ -156,3 +180,20 @@ func TestFormatRootStructName(t *testing.T) {
 		})
 	}
 }
+
+func TestPointerIdentifier(t *testing.T) {
+	cases := []struct {
+		pointer  string
+		expected string
+	}{
+		{"/$defs/address", "address"},
+		{"/properties/a~1b", "a/b"},
+		{"/allOf/0", "allOf_0"},
+	}
+
+	for _, c := range cases {
+		if actual := getPointerIdentifier(c.pointer); actual != c.expected {
+			t.Errorf("for %s, expected %s, got %s", c.pointer, c.expected, actual)
+		}
+	}
+}
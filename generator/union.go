@@ -0,0 +1,501 @@
+package generator
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/dave/jennifer/jen"
+)
+
+// unionBranches returns the branches of a oneOf, or else of an anyOf.
+func unionBranches(schema *jsonschema.Schema) []jsonschema.Schema {
+	if len(schema.OneOf) > 0 {
+		return schema.OneOf
+	}
+	return schema.AnyOf
+}
+
+// isUnion reports whether the schema is a oneOf or anyOf of several
+// alternatives, neither a nullable schema nor a documented enum.
+// Branches only made of constraints on the type of the schema,
+// e.g. {"format": "email"} or {"required": ["id"]}, are validation
+// rather than alternatives, the schema is generated as its type.
+func isUnion(schema *jsonschema.Schema) bool {
+	if schema.Ref != "" || isEnum(schema) || len(schema.Properties) > 0 {
+		return false
+	}
+	if _, ok := schema.UnwrapNullableSchema(); ok {
+		return false
+	}
+	branches := unionBranches(schema)
+	if len(branches) < 2 {
+		return false
+	}
+	for i := range branches {
+		if !isConstraint(&branches[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConstraint reports whether the branch of a union has no type of its own,
+// neither a type, a $ref, properties, items nor alternatives.
+func isConstraint(branch *jsonschema.Schema) bool {
+	return branch.Ref == "" && len(branch.Type) == 0 && branch.SchemaType() == "" &&
+		len(branch.Properties) == 0 && len(branch.PrefixItems) == 0 && branch.Items == nil &&
+		len(branch.AllOf) == 0 && len(branch.AnyOf) == 0 && len(branch.OneOf) == 0
+}
+
+// unionVariant is a branch of a union.
+type unionVariant struct {
+	schema *jsonschema.Schema
+	// target is the branch, or the schema it refers to
+	target *jsonschema.Schema
+	// value is the value of the discriminator property
+	value string
+}
+
+// discriminatedUnion is a union whose branches are told apart
+// by the value of a property.
+type discriminatedUnion struct {
+	property string
+	variants []unionVariant
+}
+
+// discriminatorOf returns the discriminated union of the schema, nil if the
+// schema is not a union or its branches can not be told apart.
+//
+// The discriminator is the property whose const, or single enum value, is a
+// different string in each branch, or the propertyName of an OpenAPI
+// discriminator. The values of an OpenAPI discriminator are the keys of its
+// mapping, or else the const of the property, or else the name of the
+// referred schema.
+func (g *Generator) discriminatorOf(schema *jsonschema.Schema) *discriminatedUnion {
+	if !isUnion(schema) {
+		return nil
+	}
+
+	branches := unionBranches(schema)
+	variants := make([]unionVariant, len(branches))
+	for i := range branches {
+		if _, ok := g.schemaOverride(&branches[i]); ok {
+			// an existing type, which can not implement the variant interface
+			return nil
+		}
+		variants[i] = unionVariant{schema: &branches[i], target: &branches[i]}
+		if branches[i].Ref != "" {
+			chain, err := g.resolver.GetRefChain(&branches[i])
+			if err != nil {
+				return nil
+			}
+			variants[i].target = chain[len(chain)-1]
+		}
+	}
+
+	if d := schema.Discriminator; d != nil && d.PropertyName != "" {
+		mapped := map[*jsonschema.Schema]string{}
+		for value, ref := range d.Mapping {
+			target, _, err := g.resolver.resolve(schema, ref)
+			if err != nil || target == nil {
+				return nil
+			}
+			mapped[target] = value
+		}
+		for i, v := range variants {
+			value, ok := mapped[v.target]
+			if !ok {
+				value, ok = g.constString(v.target.Properties[d.PropertyName])
+			}
+			if !ok && v.schema.Ref != "" {
+				value, ok = trimSchemaExt(path.Base(v.schema.Ref)), true
+			}
+			if !ok {
+				return nil
+			}
+			variants[i].value = value
+		}
+		if !distinctValues(variants) {
+			return nil
+		}
+		return &discriminatedUnion{property: d.PropertyName, variants: variants}
+	}
+
+	var names []string
+	for name := range variants[0].target.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+NAMES:
+	for _, name := range names {
+		for i, v := range variants {
+			value, ok := g.constString(v.target.Properties[name])
+			if !ok {
+				continue NAMES
+			}
+			variants[i].value = value
+		}
+		if distinctValues(variants) {
+			return &discriminatedUnion{property: name, variants: variants}
+		}
+	}
+	return nil
+}
+
+// constString returns the const, or single enum value, of the schema
+// or the schema it refers to, if it is a string.
+func (g *Generator) constString(schema *jsonschema.Schema) (string, bool) {
+	if schema == nil {
+		return "", false
+	}
+	if schema.Ref != "" {
+		chain, err := g.resolver.GetRefChain(schema)
+		if err != nil {
+			return "", false
+		}
+		schema = chain[len(chain)-1]
+	}
+	value := schema.Const
+	if value == nil && len(schema.Enum) == 1 {
+		value = schema.Enum[0]
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+func distinctValues(variants []unionVariant) bool {
+	seen := map[string]bool{}
+	for _, v := range variants {
+		if seen[v.value] {
+			return false
+		}
+		seen[v.value] = true
+	}
+	return true
+}
+
+// variantTypeName returns the name of the type of a branch of a union.
+// A branch referring to a schema is the type of the reference,
+// an inline branch is generated as a named type on its own, named after
+// the union and suffix, e.g. "ShapeCircle".
+func (g *Generator) variantTypeName(id string, v unionVariant, suffix string) string {
+	if v.schema.Ref != "" {
+		chain, _ := g.resolver.GetRefChain(v.schema)
+		named := chain[0]
+		if g.opts.CollapseRefChains {
+			named = v.target
+		}
+		return g.refTypeName(named)
+	}
+	if _, ok := g.typeNames[v.schema]; !ok {
+		g.typeNames[v.schema] = id + g.toGolangName(suffix)
+	}
+	return g.nestedType(v.schema)
+}
+
+// generateDiscriminatedUnion generates the union as a struct embedding a
+// sealed interface implemented by the types of the branches, e.g.
+//
+//	type Shape struct {
+//		ShapeVariant
+//	}
+//
+// with MarshalJSON encoding the variant, and UnmarshalJSON decoding the
+// variant chosen by the value of the discriminator property.
+func (g *Generator) generateDiscriminatedUnion(id string, union *discriminatedUnion, file *jen.File) {
+	variant := id + "Variant"
+	marker := "is" + variant
+
+	file.Type().Id(id).Struct(jen.Id(variant)).Line()
+
+	var names []string
+	for _, v := range union.variants {
+		names = append(names, g.variantTypeName(id, v, v.value))
+	}
+
+	file.Commentf("%s is implemented by the variants of %s: %s.", variant, id, strings.Join(names, ", "))
+	file.Type().Id(variant).Interface(jen.Id(marker).Params()).Line()
+	for _, name := range names {
+		file.Func().Params(jen.Id(name)).Id(marker).Params().Block().Line()
+	}
+
+	file.Comment("MarshalJSON encodes the variant, null if not set.")
+	file.Func().Params(jen.Id("v").Id(id)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("v").Dot(variant))),
+	).Line()
+
+	var cases []jen.Code
+	for i, v := range union.variants {
+		cases = append(cases, jen.Case(jen.Lit(v.value)).Block(
+			jen.Var().Id("variant").Id(names[i]),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("variant")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.Id("v").Dot(variant).Op("=").Id("variant"),
+		))
+	}
+	cases = append(cases, jen.Default().Block(
+		jen.Return(jen.Qual("fmt", "Errorf").Call(
+			jen.Lit("unknown "+id+" "+union.property+": %q"),
+			jen.Id("discriminator").Dot("Value"),
+		)),
+	))
+
+	file.Commentf("UnmarshalJSON decodes the variant of %s given by the %q property.", id, union.property)
+	file.Func().Params(jen.Id("v").Op("*").Id(id)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+		jen.If(jen.String().Call(jen.Id("b")).Op("==").Lit("null")).Block(
+			jen.Id("v").Dot(variant).Op("=").Nil(),
+			jen.Return(jen.Nil()),
+		),
+		jen.Var().Id("discriminator").Struct(
+			jen.Id("Value").String().Tag(map[string]string{"json": union.property}),
+		),
+		jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("discriminator")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())),
+		jen.Switch(jen.Id("discriminator").Dot("Value")).Block(cases...),
+		jen.Return(jen.Nil()),
+	).Line()
+}
+
+// unionAlternative is a branch of an untagged union,
+// generated as a pointer field of the union struct.
+type unionAlternative struct {
+	field string
+	t     jen.Code
+	// kind is the JSON type of the branch, empty if any
+	kind     jsonschema.Type
+	required []string
+}
+
+// unionAlternatives returns the branches of an untagged union,
+// without the null branch, and whether there is one.
+func (g *Generator) unionAlternatives(id string, schema *jsonschema.Schema) ([]unionAlternative, bool) {
+	var alternatives []unionAlternative
+	nullable := false
+	taken := map[string]bool{}
+	branches := unionBranches(schema)
+	for i := range branches {
+		v := unionVariant{schema: &branches[i], target: &branches[i]}
+		spec, overridden := g.schemaOverride(v.schema)
+		unresolved := false
+		if v.schema.Ref != "" {
+			chain, err := g.resolver.GetRefChain(v.schema)
+			if err != nil {
+				v.target, unresolved = &jsonschema.Schema{}, true
+			} else {
+				v.target = chain[len(chain)-1]
+			}
+		}
+
+		kind := v.target.SchemaType()
+		if isConstraint(v.schema) {
+			// constrains the values of the type of the union
+			kind = schema.SchemaType()
+			constrained := *v.schema
+			constrained.Type = schema.Type
+			v.schema, v.target = &constrained, &constrained
+		}
+		if kind == jsonschema.TypeNull {
+			nullable = true
+			continue
+		}
+
+		var alternative unionAlternative
+		switch {
+		case overridden:
+			alternative.field, alternative.t = g.overrideFieldName(spec), generateOverrideType(spec, v.target, true)
+		case unresolved:
+			alternative.field, alternative.t = "Raw", jen.Qual("encoding/json", "RawMessage")
+		case v.schema.Ref != "":
+			name := g.variantTypeName(id, v, "")
+			alternative.field, alternative.t = name, jen.Id(name)
+		case isEnum(v.schema) || isUnion(v.schema) || kind == jsonschema.TypeObject:
+			suffix := v.schema.Title
+			if suffix == "" {
+				suffix = string(kind)
+			}
+			if suffix == "" {
+				suffix = "value"
+			}
+			name := g.variantTypeName(id, v, suffix)
+			alternative.field, alternative.t = strings.TrimPrefix(name, id), jen.Id(name)
+		case kind == jsonschema.TypeArray:
+			alternative.field = "Array"
+			if items := v.schema.Items; items != nil && items.Ref != "" {
+				if chain, err := g.resolver.GetRefChain(items); err == nil {
+					alternative.field = g.SchemaTypeName(chain[0]) + "List"
+				}
+			}
+			alternative.t = g.generateSchemaType(v.schema, true)
+		default:
+			alternative.field = g.toGolangName(string(kind))
+			if alternative.field == "" {
+				alternative.field = "Value"
+			}
+			alternative.t = g.generateSchemaType(v.schema, true)
+		}
+		if taken[alternative.field] {
+			alternative.field += strconv.Itoa(i + 1)
+		}
+		taken[alternative.field] = true
+		alternative.kind = kind
+		if kind == jsonschema.TypeObject {
+			alternative.required = v.target.Required
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	return alternatives, nullable
+}
+
+// char returns a rune literal.
+func char(c rune) jen.Code {
+	return jen.Id(strconv.QuoteRune(c))
+}
+
+// kindCondition returns the condition on the first byte of the trimmed
+// JSON value for it to be of the kind, nil if any kind matches.
+func kindCondition(kind jsonschema.Type) jen.Code {
+	first := jen.Id("trimmed").Index(jen.Lit(0))
+	switch kind {
+	case jsonschema.TypeObject:
+		return jen.Add(first).Op("==").Add(char('{'))
+	case jsonschema.TypeArray:
+		return jen.Add(first).Op("==").Add(char('['))
+	case jsonschema.TypeString:
+		return jen.Add(first).Op("==").Add(char('"'))
+	case jsonschema.TypeBoolean:
+		return jen.Parens(jen.Add(first).Op("==").Add(char('t')).Op("||").Add(first).Op("==").Add(char('f')))
+	case jsonschema.TypeNumber:
+		return jen.Parens(jen.Add(first).Op("==").Add(char('-')).Op("||").Add(first).Op(">=").Add(char('0')).Op("&&").Add(first).Op("<=").Add(char('9')))
+	case jsonschema.TypeInteger:
+		return jen.Add(kindCondition(jsonschema.TypeNumber)).Op("&&").Op("!").Qual("bytes", "ContainsAny").Call(jen.Id("trimmed"), jen.Lit(".eE"))
+	}
+	return nil
+}
+
+// generateUntaggedUnion generates the union as a struct with a pointer field
+// per branch, e.g.
+//
+//	type Value struct {
+//		String     *string
+//		Object     *Object
+//		ObjectList *[]Object
+//	}
+//
+// with UnmarshalJSON setting the field of the branch matching the JSON type
+// and the required properties of the value, an error if several branches of
+// a oneOf match, and MarshalJSON encoding the field which is set.
+func (g *Generator) generateUntaggedUnion(schema *jsonschema.Schema, id string, file *jen.File) {
+	alternatives, nullable := g.unionAlternatives(id, schema)
+	oneOf := len(schema.OneOf) > 0
+
+	var fields []jen.Code
+	for _, a := range alternatives {
+		fields = append(fields, jen.Id(a.field).Op("*").Add(a.t))
+	}
+	file.Type().Id(id).Struct(fields...).Line()
+
+	var marshal []jen.Code
+	for _, a := range alternatives {
+		marshal = append(marshal, jen.Case(jen.Id("v").Dot(a.field).Op("!=").Nil()).Block(
+			jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("v").Dot(a.field))),
+		))
+	}
+	file.Comment("MarshalJSON encodes the branch which is set, null if none.")
+	file.Func().Params(jen.Id("v").Id(id)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Switch().Block(marshal...),
+		jen.Return(jen.Index().Byte().Call(jen.Lit("null")), jen.Nil()),
+	).Line()
+
+	nullResult := jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(id + " can not be null")))
+	if nullable {
+		nullResult = jen.Return(jen.Nil())
+	}
+	body := []jen.Code{
+		jen.Op("*").Id("v").Op("=").Id(id).Values(),
+		jen.Id("trimmed").Op(":=").Qual("bytes", "TrimSpace").Call(jen.Id("b")),
+		jen.If(jen.Len(jen.Id("trimmed")).Op("==").Lit(0)).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("empty " + id))),
+		),
+		jen.If(jen.String().Call(jen.Id("trimmed")).Op("==").Lit("null")).Block(nullResult),
+	}
+
+	hasRequired := false
+	for _, a := range alternatives {
+		hasRequired = hasRequired || len(a.required) > 0
+	}
+	if hasRequired {
+		body = append(body,
+			jen.Var().Id("object").Map(jen.String()).Qual("encoding/json", "RawMessage"),
+			jen.If(jen.Id("trimmed").Index(jen.Lit(0)).Op("==").Add(char('{'))).Block(
+				jen.If(
+					jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("object")),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err())),
+			),
+		)
+	}
+
+	body = append(body, jen.Var().Id("matches").Index().String())
+	for _, a := range alternatives {
+		var conditions []jen.Code
+		if !oneOf {
+			// the first matching branch of an anyOf
+			conditions = append(conditions, jen.Len(jen.Id("matches")).Op("==").Lit(0))
+		}
+		if c := kindCondition(a.kind); c != nil {
+			conditions = append(conditions, c)
+		}
+		for _, name := range a.required {
+			conditions = append(conditions, jen.Id("object").Index(jen.Lit(name)).Op("!=").Nil())
+		}
+		decode := jen.Block(
+			jen.Var().Id("value").Add(a.t),
+			jen.If(
+				jen.Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("value")).Op("==").Nil(),
+			).Block(
+				jen.Id("v").Dot(a.field).Op("=").Op("&").Id("value"),
+				jen.Id("matches").Op("=").Append(jen.Id("matches"), jen.Lit(a.field)),
+			),
+		)
+		if len(conditions) == 0 {
+			body = append(body, decode)
+			continue
+		}
+		var condition *jen.Statement
+		for i, c := range conditions {
+			if i == 0 {
+				condition = jen.Add(c)
+				continue
+			}
+			condition.Op("&&").Add(c)
+		}
+		body = append(body, jen.If(condition).Add(decode))
+	}
+
+	cases := []jen.Code{
+		jen.Case(jen.Len(jen.Id("matches")).Op("==").Lit(0)).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("no branch of "+id+" matches %s"), jen.Id("trimmed"))),
+		),
+	}
+	if oneOf {
+		cases = append(cases, jen.Case(jen.Len(jen.Id("matches")).Op(">").Lit(1)).Block(
+			jen.Op("*").Id("v").Op("=").Id(id).Values(),
+			jen.Return(jen.Qual("fmt", "Errorf").Call(
+				jen.Lit("ambiguous "+id+", matching %s"),
+				jen.Qual("strings", "Join").Call(jen.Id("matches"), jen.Lit(", ")),
+			)),
+		))
+	}
+	body = append(body, jen.Switch().Block(cases...))
+	body = append(body, jen.Return(jen.Nil()))
+
+	file.Commentf("UnmarshalJSON decodes the branch of %s matching the JSON type and the required properties of the value.", id)
+	file.Func().Params(jen.Id("v").Op("*").Id(id)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(body...).Line()
+}
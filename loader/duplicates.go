@@ -0,0 +1,154 @@
+package loader
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/go-errors/errors"
+)
+
+// Dedupe keeps one schema per $id, in the order of schemas.
+//
+// Schemas declaring the same $id are merged when they are byte for byte
+// identical, e.g. vendored copies of the same file. Otherwise the one
+// loaded from the file matching the first Override pattern is kept.
+// Conflicting schemas that no pattern orders are reported as errors,
+// listing the sources of all the conflicting schemas.
+func (l *Loader) Dedupe(schemas []*jsonschema.Schema) ([]*jsonschema.Schema, error) {
+	keep := make(map[*jsonschema.Schema]bool)
+	byID := make(map[string][]*jsonschema.Schema)
+	var ids []string
+	for _, schema := range schemas {
+		id := canonicalID(schema.ID)
+		if id == "" {
+			// not identified, kept as is
+			keep[schema] = true
+			continue
+		}
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+		byID[id] = append(byID[id], schema)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		schema, err := l.pick(id, byID[id])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		keep[schema] = true
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, errors.New(err)
+	}
+
+	deduped := make([]*jsonschema.Schema, 0, len(keep))
+	for _, schema := range schemas {
+		if keep[schema] {
+			deduped = append(deduped, schema)
+		}
+	}
+	return deduped, nil
+}
+
+// pick returns the schema to keep among the ones declaring the same $id.
+func (l *Loader) pick(id string, duplicates []*jsonschema.Schema) (*jsonschema.Schema, error) {
+	best := duplicates[0]
+	bestRank := l.overrideRank(best)
+	conflicts := []*jsonschema.Schema{best}
+	for _, schema := range duplicates[1:] {
+		if l.identical(best, schema) {
+			continue
+		}
+		rank := l.overrideRank(schema)
+		switch {
+		case rank < bestRank:
+			best, bestRank = schema, rank
+			conflicts = []*jsonschema.Schema{schema}
+		case rank == bestRank:
+			conflicts = append(conflicts, schema)
+		}
+	}
+
+	// identical copies of the best one do not conflict with it
+	var different []string
+	for _, schema := range conflicts {
+		if schema == best || !l.identical(best, schema) {
+			different = append(different, sourceName(schema))
+		}
+	}
+	if len(different) > 1 {
+		return nil, errors.Errorf("duplicate $id %s with different content in %s, choose one with an override pattern", id, strings.Join(different, ", "))
+	}
+	return best, nil
+}
+
+// overrideRank returns the index of the first Override pattern
+// matching the file of the schema, len(Override) if none.
+// Patterns match the path of the file relative to RootDir,
+// or to the working directory without RootDir, or as given.
+func (l *Loader) overrideRank(schema *jsonschema.Schema) int {
+	if schema.Location != nil {
+		names := []string{filepath.ToSlash(schema.Location.File)}
+		if rel, err := l.relativePath(schema.Location.File); err == nil {
+			names = append(names, rel)
+		}
+		for i, pattern := range l.opts.Override {
+			for _, name := range names {
+				if matchAny([]string{pattern}, name) {
+					return i
+				}
+			}
+		}
+	}
+	return len(l.opts.Override)
+}
+
+// relativePath returns the slash separated path of the file relative to
+// RootDir, or to the working directory without RootDir.
+func (l *Loader) relativePath(file string) (string, error) {
+	dir, err := filepath.Abs(l.opts.RootDir)
+	if err != nil {
+		return "", err
+	}
+	file, err = filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (l *Loader) identical(a, b *jsonschema.Schema) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rawA, okA := l.raw[a]
+	rawB, okB := l.raw[b]
+	return okA && okB && bytes.Equal(rawA, rawB)
+}
+
+func sourceName(schema *jsonschema.Schema) string {
+	if schema.Location != nil {
+		return schema.Location.String()
+	}
+	return schema.ID
+}
+
+// canonicalID returns the $id without empty fragment,
+// "a.json#" and "a.json" identifying the same schema resource.
+func canonicalID(id string) string {
+	u, err := url.Parse(id)
+	if err != nil {
+		return id
+	}
+	u.Fragment = ""
+	return u.String()
+}
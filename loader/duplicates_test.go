@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	a := write("a.json", `{"$id": "https://example.com/a.json", "title": "a"}`)
+	vendoredA := write("vendor/a.json", `{"$id": "https://example.com/a.json", "title": "a"}`)
+	b := write("b.json", `{"$id": "https://example.com/b.json", "title": "b"}`)
+	patchedB := write("patched/b.json", `{"$id": "https://example.com/b.json", "title": "patched b"}`)
+
+	schemas, err := New(&ParseOptions{}).LoadAll([]string{a, vendoredA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 1 {
+		t.Errorf("expected identical schemas to be deduplicated, got %d", len(schemas))
+	}
+
+	_, err = New(&ParseOptions{}).LoadAll([]string{b, patchedB})
+	if err == nil {
+		t.Fatal("expected a conflict")
+	}
+	for _, expected := range []string{"duplicate $id https://example.com/b.json", "b.json:1:1", "patched/b.json:1:1"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in %v", expected, err)
+		}
+	}
+
+	schemas, err = New(&ParseOptions{Override: []string{"**/patched/*.json"}}).LoadAll([]string{a, b, patchedB, vendoredA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, schema := range schemas {
+		titles = append(titles, schema.Title)
+	}
+	if strings.Join(titles, ",") != "a,patched b" {
+		t.Errorf("expected the patched b to override b, got %v", titles)
+	}
+}
+
+func TestOverrideRelativeToRootDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, title := range map[string]string{"schema/b.json": "b", "vendor/b.json": "vendored b"} {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(`{"$id": "https://example.com/b.json", "title": "`+title+`"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, override := range []string{"schema/**", "vendor/b.json"} {
+		t.Run(override, func(t *testing.T) {
+			opts := &ParseOptions{RootDir: dir, Override: []string{override}}
+			schemas, err := New(opts).LoadAll([]string{filepath.Join(dir, "vendor/b.json"), filepath.Join(dir, "schema/b.json")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := filepath.Join(dir, override[:strings.Index(override, "/")], "b.json")
+			if len(schemas) != 1 || schemas[0].Location.File != expected {
+				t.Errorf("expected the schema of %s, got %v", expected, schemas)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package loader
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// defaultInclude is used to filter files found by walking a directory
+// when no include pattern is given.
+var defaultInclude = []string{"*.json", "*.jsonc", "*.json5"}
+
+// ExpandPaths expands the given arguments to a sorted list of schema files.
+//
+// An argument may be:
+//   - a regular file, which is always included
+//   - a directory, which is walked recursively, following symbolic links
+//   - a glob pattern, such as "schema/**/*.json", in which "**" matches
+//     any number of directories
+//
+// Files found by walking a directory are filtered by opts.Include and
+// opts.Exclude, files matching a glob only by opts.Exclude, the glob
+// selecting the files itself. A pattern without a "/" is matched against
+// the file name, otherwise against the slash separated path.
+// "-" is kept as is, meaning stdin.
+func (l *Loader) ExpandPaths(args []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(file string) {
+		if seen[file] {
+			return
+		}
+		seen[file] = true
+		files = append(files, file)
+	}
+
+	for _, arg := range args {
+		if arg == "-" {
+			add(arg)
+			continue
+		}
+
+		if !hasMeta(arg) {
+			info, err := os.Stat(arg)
+			if err != nil {
+				return nil, errors.New(err)
+			}
+			if !info.IsDir() {
+				add(filepath.Clean(arg))
+				continue
+			}
+			found, err := l.walk(arg, nil)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range found {
+				add(file)
+			}
+			continue
+		}
+
+		found, err := l.glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range found {
+			add(file)
+		}
+	}
+
+	if len(args) > 0 && len(files) == 0 {
+		return nil, errors.Errorf("no schema files found in %s", strings.Join(args, ", "))
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// glob returns all files matching pattern.
+func (l *Loader) glob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.Errorf("invalid glob pattern %q: %v", pattern, err)
+	}
+
+	// walk from the longest directory prefix without meta characters
+	segments := strings.Split(pattern, "/")
+	i := 0
+	for i < len(segments)-1 && !hasMeta(segments[i]) {
+		i++
+	}
+	root := strings.Join(segments[:i], "/")
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return l.walk(filepath.FromSlash(root), func(file string) bool {
+		return matchPath(pattern, filepath.ToSlash(file))
+	})
+}
+
+// walk returns all regular files under root accepted by match,
+// or else by the include filters, and not excluded.
+// Symbolic links to directories are followed, like find -L,
+// except the ones to a directory being walked or its ancestors, which would loop.
+func (l *Loader) walk(root string, match func(string) bool) ([]string, error) {
+	include := l.opts.Include
+	if len(include) == 0 {
+		include = defaultInclude
+	}
+	if match == nil {
+		match = func(file string) bool {
+			return matchAny(include, filepath.ToSlash(file))
+		}
+	}
+
+	var files []string
+	// walking is the real paths of the directories being walked
+	walking := make(map[string]bool)
+	var walkDir func(dir string) error
+	walkDir = func(dir string) error {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		walking[real] = true
+		defer delete(walking, real)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			file := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if entry.Type()&os.ModeSymlink != 0 {
+					target, err := filepath.EvalSymlinks(file)
+					if err != nil {
+						return err
+					}
+					rel, err := filepath.Rel(target, real)
+					if walking[target] || err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+						// a link to a directory being walked, or to an ancestor
+						continue
+					}
+				}
+				if err := walkDir(file); err != nil {
+					return err
+				}
+				continue
+			}
+			if !info.Mode().IsRegular() || !match(file) || matchAny(l.opts.Exclude, filepath.ToSlash(file)) {
+				continue
+			}
+			files = append(files, file)
+		}
+		return nil
+	}
+	if err := walkDir(root); err != nil {
+		return nil, errors.New(err)
+	}
+	return files, nil
+}
+
+func matchAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+			continue
+		}
+		if matchPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether the slash separated name matches pattern,
+// where a "**" segment matches zero or more path segments.
+func matchPath(pattern, name string) bool {
+	return matchSegments(
+		strings.Split(path.Clean(pattern), "/"),
+		strings.Split(path.Clean(name), "/"),
+	)
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
@@ -0,0 +1,145 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.json", "a.json", true},
+		{"*.json", "dir/a.json", false},
+		{"dir/*.json", "dir/a.json", true},
+		{"dir/**/*.json", "dir/a.json", true},
+		{"dir/**/*.json", "dir/sub/deep/a.json", true},
+		{"dir/**/*.json", "other/a.json", false},
+		{"**/testdata/**", "dir/testdata/a.json", true},
+		{"**/testdata/**", "dir/a.json", false},
+	}
+
+	for _, c := range cases {
+		if actual := matchPath(c.pattern, c.name); actual != c.expected {
+			t.Errorf("matchPath(%q, %q): expected %v, got %v", c.pattern, c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"b.json",
+		"a.json",
+		"readme.md",
+		"sub/c.json",
+		"sub/testdata/d.json",
+		"sub/e.yaml",
+	} {
+		file = filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a linked directory, and a link to its parent not walked again
+	if err := os.Symlink(filepath.Join(dir, "sub", "testdata"), filepath.Join(dir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, filepath.Join(dir, "sub", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "directory",
+			args:     []string{dir},
+			expected: []string{"a.json", "b.json", "linked/d.json", "sub/c.json", "sub/testdata/d.json"},
+		},
+		{
+			name:     "directory with exclude",
+			args:     []string{dir},
+			exclude:  []string{"**/testdata/**", "**/linked/**"},
+			expected: []string{"a.json", "b.json", "sub/c.json"},
+		},
+		{
+			name:     "glob",
+			args:     []string{filepath.Join(dir, "sub", "**", "*.json")},
+			expected: []string{"sub/c.json", "sub/testdata/d.json"},
+		},
+		{
+			name:     "glob not filtered by the default include",
+			args:     []string{filepath.Join(dir, "**", "*.yaml")},
+			expected: []string{"sub/e.yaml"},
+		},
+		{
+			name:     "file and duplicated glob",
+			args:     []string{filepath.Join(dir, "readme.md"), filepath.Join(dir, "*.json"), filepath.Join(dir, "a.json")},
+			expected: []string{"a.json", "b.json", "readme.md"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l := New(&ParseOptions{Exclude: c.exclude})
+			actual, err := l.ExpandPaths(c.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expected []string
+			for _, file := range c.expected {
+				expected = append(expected, filepath.Join(dir, filepath.FromSlash(file)))
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestFileURIsUnderRootDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"schema/a.json", "schema/sub/b.json", "schemas/c.json"} {
+		file = filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := New(&ParseOptions{RootDir: filepath.Join(dir, "schema")})
+	files, err := l.ExpandPaths([]string{filepath.Join(dir, "schema"), filepath.Join(dir, "schemas", "*.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, file := range files {
+		uri, err := l.ParseFileURI(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uris = append(uris, uri.String())
+	}
+	expected := []string{
+		"file:///a.json",
+		"file:///sub/b.json",
+		// not under the root directory, despite the common prefix
+		"file://" + filepath.ToSlash(filepath.Join(dir, "schemas", "c.json")),
+	}
+	if !reflect.DeepEqual(uris, expected) {
+		t.Errorf("expected %v, got %v", expected, uris)
+	}
+}
@@ -1,12 +1,16 @@
 package loader
 
 import (
+	"fmt"
 	"io"
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/RyoJerryYu/go-jsonschema"
 	"github.com/go-errors/errors"
@@ -23,14 +27,42 @@ func abs(name string) (string, error) {
 type ParseOptions struct {
 	RootDir string
 	BaseURI string
+	// Include and Exclude filter the files found in directories and globs.
+	// Include defaults to "*.json", "*.jsonc" and "*.json5".
+	Include []string
+	Exclude []string
+	// StdinURI is the URI of the schema read from stdin when it has no $id.
+	// A value without scheme is mapped like a file path.
+	StdinURI string
+	// Format is the syntax of the inputs, detected from the file extension by default.
+	Format Format
+	// Jobs is the maximum number of files loaded in parallel,
+	// defaults to GOMAXPROCS.
+	Jobs int
+	// Override is the patterns of the files whose schemas take precedence
+	// over the schemas with the same $id in other files, by decreasing priority.
+	Override []string
 }
 
 type Loader struct {
 	opts *ParseOptions
+
+	mu sync.Mutex
+	// raw is the loaded schemas as written in their source
+	raw map[*jsonschema.Schema][]byte
 }
 
 func New(opts *ParseOptions) *Loader {
-	return &Loader{opts: opts}
+	return &Loader{
+		opts: opts,
+		raw:  make(map[*jsonschema.Schema][]byte),
+	}
+}
+
+func (l *Loader) setRaw(schema *jsonschema.Schema, raw []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.raw[schema] = raw
 }
 
 func (l *Loader) ParseFileURI(file string) (*url.URL, error) {
@@ -38,7 +70,16 @@ func (l *Loader) ParseFileURI(file string) (*url.URL, error) {
 	if err != nil {
 		return nil, err
 	}
-	abPath = strings.TrimPrefix(abPath, l.opts.RootDir)
+	if l.opts.RootDir != "" {
+		rootDir, err := abs(l.opts.RootDir)
+		if err != nil {
+			return nil, err
+		}
+		// the files outside the root directory keep their absolute path
+		if rel, err := filepath.Rel(rootDir, abPath); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			abPath = filepath.ToSlash(rel)
+		}
+	}
 	if !strings.HasPrefix(abPath, "/") {
 		abPath = "/" + abPath
 	}
@@ -58,29 +99,69 @@ func (l *Loader) ParseFileURI(file string) (*url.URL, error) {
 	return baseUri, nil
 }
 
+// LoadAll loads all the schemas in filePaths, "-" meaning stdin,
+// or stdin if filePaths is empty.
+// Files are loaded in parallel, bounded by the Jobs option,
+// and the schemas are returned in the sorted order of filePaths.
+// All the load errors are reported, not only the first one.
+// Schemas declaring the same $id are deduplicated, see Dedupe.
 func (l *Loader) LoadAll(filePaths []string) ([]*jsonschema.Schema, error) {
 	if len(filePaths) == 0 {
-		schema, err := l.LoadStdin()
+		schemas, err := l.LoadStdin()
 		if err != nil {
 			return nil, errors.New(err)
 		}
 
-		return []*jsonschema.Schema{schema}, nil
+		return l.Dedupe(schemas)
 	}
 
 	sort.Strings(filePaths)
 
-	schemas := make([]*jsonschema.Schema, len(filePaths))
-	for i, filePath := range filePaths {
-		schema, err := l.LoadFile(filePath)
-		if err != nil {
-			return nil, errors.New(err)
+	results := make([][]*jsonschema.Schema, len(filePaths))
+	errs := make([]error, len(filePaths))
+	load := func(i int) {
+		if filePaths[i] == "-" {
+			results[i], errs[i] = l.LoadStdin()
+			return
 		}
+		schema, err := l.LoadFile(filePaths[i])
+		results[i], errs[i] = []*jsonschema.Schema{schema}, err
+	}
 
-		schemas[i] = schema
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for j := 0; j < l.jobs() && j < len(filePaths); j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				load(i)
+			}
+		}()
 	}
+	for i := range filePaths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 
-	return schemas, nil
+	if err := errors.Join(errs...); err != nil {
+		return nil, errors.New(err)
+	}
+
+	schemas := make([]*jsonschema.Schema, 0, len(filePaths))
+	for _, result := range results {
+		schemas = append(schemas, result...)
+	}
+
+	return l.Dedupe(schemas)
+}
+
+func (l *Loader) jobs() int {
+	if l.opts.Jobs > 0 {
+		return l.opts.Jobs
+	}
+	return runtime.GOMAXPROCS(0)
 }
 
 // LoadFile loads a JSON schema from filePath
@@ -98,14 +179,125 @@ func (l *Loader) LoadFile(filePath string) (*jsonschema.Schema, error) {
 		return nil, errors.New(err)
 	}
 
-	return l.loadInput(input, fileURI)
+	return l.loadInput(input, filePath, fileURI)
+}
+
+// LoadStdin loads all the JSON schemas concatenated in stdin.
+// Schemas without $id are identified by the StdinURI option,
+// see StdinDocumentURI.
+func (l *Loader) LoadStdin() ([]*jsonschema.Schema, error) {
+	return l.loadInputs(os.Stdin)
+}
+
+// ParseStdinURI returns the URI of the stdin document.
+// A StdinURI with a scheme is used as is, otherwise it is parsed
+// as a file path like the input files.
+// An empty StdinURI gives an empty URI.
+func (l *Loader) ParseStdinURI() (*url.URL, error) {
+	if l.opts.StdinURI == "" {
+		return &url.URL{}, nil
+	}
+
+	u, err := url.Parse(l.opts.StdinURI)
+	if err == nil && u.Scheme != "" {
+		return u, nil
+	}
+
+	return l.ParseFileURI(l.opts.StdinURI)
+}
+
+// StdinDocumentURI returns the URI of the index-th document in stdin.
+// The first document is identified by the stdin URI,
+// the following ones by the stdin URI with the index appended to the file name,
+// e.g. "schema.json", "schema_1.json", "schema_2.json",
+// or to an opaque URI, e.g. "urn:corp:schema", "urn:corp:schema_1".
+// Without stdin URI, the documents after the first are "stdin_1.json",
+// "stdin_2.json"...
+func StdinDocumentURI(stdinURI *url.URL, index int) *url.URL {
+	u := *stdinURI
+	if index == 0 {
+		return &u
+	}
+
+	if u.Opaque != "" {
+		u.Opaque = appendIndex(u.Opaque, index)
+		return &u
+	}
+	if u.Path == "" {
+		u.Path = "stdin.json"
+	}
+	u.Path = appendIndex(u.Path, index)
+	return &u
+}
+
+// appendIndex appends the index to the name, before its extension.
+func appendIndex(name string, index int) string {
+	ext := path.Ext(name)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), index, ext)
 }
 
-func (l *Loader) LoadStdin() (*jsonschema.Schema, error) {
-	return l.loadInput(os.Stdin, &url.URL{})
+func (l *Loader) loadInputs(input io.Reader) ([]*jsonschema.Schema, error) {
+	stdinURI, err := l.ParseStdinURI()
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	src, err := readSource(input, "stdin", l.opts.Format.formatOf(stdinURI.Path))
+	if err != nil {
+		return nil, err
+	}
+	docs, err := src.documents()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("no schema found in stdin")
+	}
+
+	schemas := make([]*jsonschema.Schema, len(docs))
+	for i, doc := range docs {
+		schema, err := src.schema(doc)
+		if err != nil {
+			return nil, err
+		}
+		docURI := StdinDocumentURI(stdinURI, i)
+		if err := src.locate(schema, doc, docURI); err != nil {
+			return nil, err
+		}
+		if schema.ID == "" {
+			schema.ID = docURI.String()
+		}
+		l.setRaw(schema, src.rawOf(doc))
+		schemas[i] = schema
+	}
+
+	return schemas, nil
 }
 
-func (l *Loader) loadInput(input io.Reader, fileUri *url.URL) (*jsonschema.Schema, error) {
-	schema := jsonschema.LoadSchema(input, fileUri)
+func (l *Loader) loadInput(input io.Reader, name string, fileUri *url.URL) (*jsonschema.Schema, error) {
+	src, err := readSource(input, name, l.opts.Format.formatOf(name))
+	if err != nil {
+		return nil, err
+	}
+	docs, err := src.documents()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, errors.Errorf("%s: expected one schema, found %d JSON values", name, len(docs))
+	}
+
+	schema, err := src.schema(docs[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := src.locate(schema, docs[0], fileUri); err != nil {
+		return nil, err
+	}
+	if schema.ID == "" {
+		schema.ID = fileUri.String()
+	}
+	l.setRaw(schema, src.rawOf(docs[0]))
+
 	return schema, nil
 }
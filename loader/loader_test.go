@@ -0,0 +1,233 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+)
+
+func TestLoadConcatenatedInputs(t *testing.T) {
+	input := `{"title": "first"}
+{"$id": "https://example.com/second.json"}
+{"title": "third"}`
+
+	l := New(&ParseOptions{StdinURI: "https://example.com/schema/stdin.json"})
+	schemas, err := l.loadInputs(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"https://example.com/schema/stdin.json",
+		"https://example.com/second.json",
+		"https://example.com/schema/stdin_2.json",
+	}
+	if len(schemas) != len(expected) {
+		t.Fatalf("expected %d schemas, got %d", len(expected), len(schemas))
+	}
+	for i, schema := range schemas {
+		if schema.ID != expected[i] {
+			t.Errorf("schema %d: expected $id %s, got %s", i, expected[i], schema.ID)
+		}
+	}
+}
+
+func TestStdinDocumentURIs(t *testing.T) {
+	input := `{"title": "first"}
+{"title": "second"}
+{"title": "third"}`
+
+	cases := []struct {
+		name     string
+		stdinURI string
+		expected []string
+	}{
+		{
+			name:     "without stdin uri",
+			expected: []string{"", "stdin_1.json", "stdin_2.json"},
+		},
+		{
+			name:     "opaque uri",
+			stdinURI: "urn:corp:schema",
+			expected: []string{"urn:corp:schema", "urn:corp:schema_1", "urn:corp:schema_2"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l := New(&ParseOptions{StdinURI: c.stdinURI})
+			schemas, err := l.loadInputs(strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, schema := range schemas {
+				ids = append(ids, schema.ID)
+			}
+			if !reflect.DeepEqual(ids, c.expected) {
+				t.Errorf("expected $ids %q, got %q", c.expected, ids)
+			}
+			if _, err := l.Dedupe(schemas); err != nil {
+				t.Errorf("expected distinct schemas, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseStdinURI(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     ParseOptions
+		expected string
+	}{
+		{
+			name:     "empty",
+			expected: "",
+		},
+		{
+			name:     "absolute uri",
+			opts:     ParseOptions{StdinURI: "https://example.com/stdin.json"},
+			expected: "https://example.com/stdin.json",
+		},
+		{
+			name:     "file path under root dir",
+			opts:     ParseOptions{StdinURI: "/work/schema/stdin.json", RootDir: "/work"},
+			expected: "file:///schema/stdin.json",
+		},
+		{
+			name:     "file path with base uri",
+			opts:     ParseOptions{StdinURI: "/work/schema/stdin.json", RootDir: "/work", BaseURI: "https://example.com"},
+			expected: "https://example.com/schema/stdin.json",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			u, err := New(&c.opts).ParseStdinURI()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.String() != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, u.String())
+			}
+		})
+	}
+}
+
+func TestLoadLocations(t *testing.T) {
+	input := `{
+  // the root
+  "type": "object",
+  "properties": {
+    "name": { "type": "string" },
+    "tags": {
+      "type": "array",
+      "items": { "type": "string" }
+    }
+  }
+}`
+
+	l := New(&ParseOptions{StdinURI: "https://example.com/a.jsonc"})
+	schemas, err := l.loadInputs(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		schema   *jsonschema.Schema
+		expected jsonschema.Location
+	}{
+		{
+			name:   "root",
+			schema: schemas[0],
+			expected: jsonschema.Location{
+				URI:   "https://example.com/a.jsonc",
+				File:  "stdin",
+				Start: jsonschema.Position{Line: 1, Column: 1},
+				End:   jsonschema.Position{Line: 11, Column: 2},
+			},
+		},
+		{
+			name:   "property",
+			schema: schemas[0].Properties["name"],
+			expected: jsonschema.Location{
+				URI:   "https://example.com/a.jsonc",
+				File:  "stdin",
+				Start: jsonschema.Position{Line: 5, Column: 13},
+				End:   jsonschema.Position{Line: 5, Column: 33},
+			},
+		},
+		{
+			name:   "items",
+			schema: schemas[0].Properties["tags"].Items,
+			expected: jsonschema.Location{
+				URI:   "https://example.com/a.jsonc",
+				File:  "stdin",
+				Start: jsonschema.Position{Line: 8, Column: 16},
+				End:   jsonschema.Position{Line: 8, Column: 36},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			loc, ok := SourceOf(c.schema)
+			if !ok {
+				t.Fatal("expected a location")
+			}
+			if *loc != c.expected {
+				t.Errorf("expected %+v, got %+v", c.expected, *loc)
+			}
+		})
+	}
+}
+
+func TestLoadAllInParallel(t *testing.T) {
+	dir := t.TempDir()
+	var filePaths []string
+	for i := 9; i >= 0; i-- {
+		file := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		content := fmt.Sprintf(`{"title": "schema%d"}`, i)
+		if i == 3 || i == 7 {
+			content = `{"title": }`
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		filePaths = append(filePaths, file)
+	}
+
+	l := New(&ParseOptions{Jobs: 4})
+	_, err := l.LoadAll(filePaths)
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, name := range []string{"3.json:1:11", "7.json:1:11"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected error for %s, got %v", name, err)
+		}
+	}
+
+	filePaths = nil
+	for _, i := range []int{9, 8, 6, 5, 4, 0} {
+		filePaths = append(filePaths, filepath.Join(dir, fmt.Sprintf("%d.json", i)))
+	}
+	schemas, err := l.LoadAll(filePaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, schema := range schemas {
+		titles = append(titles, schema.Title)
+	}
+	expected := []string{"schema0", "schema4", "schema5", "schema6", "schema8", "schema9"}
+	if !reflect.DeepEqual(titles, expected) {
+		t.Errorf("expected %v, got %v", expected, titles)
+	}
+}
@@ -0,0 +1,154 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+)
+
+// SourceOf returns where the schema, or any of its subschemas,
+// was defined in the loaded sources.
+func SourceOf(schema *jsonschema.Schema) (*jsonschema.Location, bool) {
+	if schema == nil || schema.Location == nil {
+		return nil, false
+	}
+	return schema.Location, true
+}
+
+// node is the range of a JSON value in a source.
+type node struct {
+	// start and end are offsets in the source data
+	start, end int64
+	fields     map[string]*node
+	items      []*node
+}
+
+func (n *node) at(path []string) *node {
+	for _, token := range path {
+		if n == nil {
+			return nil
+		}
+		if n.fields != nil {
+			n = n.fields[token]
+			continue
+		}
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= len(n.items) {
+			return nil
+		}
+		n = n.items[i]
+	}
+	return n
+}
+
+// children returns the values of the fields or the items of the node.
+func (n *node) children() []*node {
+	children := append([]*node{}, n.items...)
+	for _, child := range n.fields {
+		children = append(children, child)
+	}
+	return children
+}
+
+// parseNodes parses the ranges of all the values in the document.
+func parseNodes(doc document) (*node, error) {
+	p := &nodeParser{
+		data:    doc.data,
+		offset:  doc.offset,
+		decoder: json.NewDecoder(bytes.NewReader(doc.data)),
+	}
+	return p.value()
+}
+
+type nodeParser struct {
+	data    []byte
+	offset  int64
+	decoder *json.Decoder
+}
+
+// next returns the offset of the next token.
+func (p *nodeParser) next() int64 {
+	o := p.decoder.InputOffset()
+	for o < int64(len(p.data)) && (isSpace(p.data[o]) || p.data[o] == ',' || p.data[o] == ':') {
+		o++
+	}
+	return o
+}
+
+func (p *nodeParser) value() (*node, error) {
+	n := &node{start: p.offset + p.next()}
+	tok, err := p.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		n.fields = make(map[string]*node)
+		for p.decoder.More() {
+			key, err := p.decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.fields[key.(string)] = child
+		}
+		if _, err := p.decoder.Token(); err != nil {
+			return nil, err
+		}
+	case json.Delim('['):
+		n.items = []*node{}
+		for p.decoder.More() {
+			child, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, child)
+		}
+		if _, err := p.decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	n.end = p.offset + p.decoder.InputOffset()
+	return n, nil
+}
+
+// locate sets the location of the schema and all its subschemas.
+func (s *source) locate(schema *jsonschema.Schema, doc document, uri *url.URL) error {
+	root, err := parseNodes(doc)
+	if err != nil {
+		return s.errorAt(doc.offset, err)
+	}
+
+	var walk func(schema *jsonschema.Schema, n *node)
+	walk = func(schema *jsonschema.Schema, n *node) {
+		if n == nil {
+			return
+		}
+		schema.Location = s.location(n, uri)
+		schema.Subschemas(func(path []string, subschema *jsonschema.Schema) {
+			walk(subschema, n.at(path))
+		})
+	}
+	walk(schema, root)
+	return nil
+}
+
+func (s *source) location(n *node, uri *url.URL) *jsonschema.Location {
+	loc := &jsonschema.Location{File: s.name}
+	if uri != nil {
+		loc.URI = uri.String()
+	}
+	loc.Start.Line, loc.Start.Column = s.position(n.start)
+	// the end is right after the last character
+	loc.End.Line, loc.End.Column = s.position(n.end - 1)
+	loc.End.Column++
+	return loc
+}
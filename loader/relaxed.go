@@ -0,0 +1,331 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"path"
+	"strings"
+)
+
+// Format is the syntax of the schema sources.
+type Format string
+
+const (
+	// FormatAuto detects the format from the file extension,
+	// ".jsonc" and ".json5" files are relaxed, others are strict JSON.
+	FormatAuto Format = ""
+	// FormatJSON is strict JSON, as accepted by encoding/json.
+	FormatJSON Format = "json"
+	// FormatJSONC is JSON with comments and trailing commas.
+	FormatJSONC Format = "jsonc"
+	// FormatJSON5 is JSON5, see https://spec.json5.org/
+	FormatJSON5 Format = "json5"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatAuto, FormatJSON, FormatJSONC, FormatJSON5:
+		return f, nil
+	case "auto":
+		return FormatAuto, nil
+	default:
+		return "", fmt.Errorf("unknown input format %q, expected one of json, jsonc, json5", s)
+	}
+}
+
+// formatOf returns the format used to read the file name.
+func (f Format) formatOf(name string) Format {
+	if f != FormatAuto {
+		return f
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jsonc":
+		return FormatJSONC
+	case ".json5":
+		return FormatJSON5
+	default:
+		return FormatJSON
+	}
+}
+
+// relax converts a JSONC or JSON5 source to strict JSON.
+// offsets[i] is the offset in src of the i-th byte of the result,
+// so that decoding errors can point into the original source.
+//
+// JSONC sources keep their length: comments and trailing commas
+// are replaced by spaces, with newlines kept.
+// Invalid input is copied as is, and left to the JSON decoder to report.
+func relax(src []byte, format Format) (out []byte, offsets []int) {
+	r := &relaxer{
+		src:     src,
+		json5:   format == FormatJSON5,
+		out:     make([]byte, 0, len(src)),
+		offsets: make([]int, 0, len(src)),
+	}
+	r.run()
+	return r.out, r.offsets
+}
+
+type relaxer struct {
+	src     []byte
+	json5   bool
+	out     []byte
+	offsets []int
+}
+
+func (r *relaxer) emit(at int, bs ...byte) {
+	for _, b := range bs {
+		r.out = append(r.out, b)
+		r.offsets = append(r.offsets, at)
+	}
+}
+
+func (r *relaxer) peek(i int) byte {
+	if i < len(r.src) {
+		return r.src[i]
+	}
+	return 0
+}
+
+func (r *relaxer) run() {
+	i := 0
+	for i < len(r.src) {
+		c := r.src[i]
+		switch {
+		case c == '"':
+			i = r.string(i)
+		case c == '\'' && r.json5:
+			i = r.string(i)
+		case c == '/' && (r.peek(i+1) == '/' || r.peek(i+1) == '*'):
+			i = r.comment(i)
+		case c == ',':
+			if next := r.src[r.skipSpace(i+1):]; len(next) > 0 && (next[0] == '}' || next[0] == ']') {
+				r.emit(i, ' ')
+			} else {
+				r.emit(i, c)
+			}
+			i++
+		case r.json5 && isIdentStart(c):
+			i = r.identifier(i)
+		case r.json5 && (c == '+' || c == '-' || c == '.' || isDigit(c)):
+			i = r.number(i)
+		default:
+			r.emit(i, c)
+			i++
+		}
+	}
+}
+
+// skipSpace returns the offset of the next byte which is neither
+// whitespace nor part of a comment.
+func (r *relaxer) skipSpace(i int) int {
+	for i < len(r.src) {
+		switch c := r.src[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '/' && r.peek(i+1) == '/':
+			for i < len(r.src) && r.src[i] != '\n' {
+				i++
+			}
+		case c == '/' && r.peek(i+1) == '*':
+			end := bytes.Index(r.src[i+2:], []byte("*/"))
+			if end < 0 {
+				return i
+			}
+			i += end + 4
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func (r *relaxer) comment(i int) int {
+	end := len(r.src)
+	if r.src[i+1] == '/' {
+		if n := bytes.IndexByte(r.src[i:], '\n'); n >= 0 {
+			end = i + n
+		}
+	} else {
+		n := bytes.Index(r.src[i+2:], []byte("*/"))
+		if n < 0 {
+			// unterminated, let the decoder report it
+			r.emit(i, r.src[i])
+			return i + 1
+		}
+		end = i + 2 + n + 2
+	}
+
+	for j := i; j < end; j++ {
+		if r.src[j] == '\n' || r.src[j] == '\r' {
+			r.emit(j, r.src[j])
+		} else {
+			r.emit(j, ' ')
+		}
+	}
+	return end
+}
+
+// string copies a quoted string starting at i, and returns the offset after it.
+// In JSON5, single quoted strings and JSON5 only escapes are converted.
+func (r *relaxer) string(i int) int {
+	quote := r.src[i]
+	r.emit(i, '"')
+	j := i + 1
+	for j < len(r.src) {
+		c := r.src[j]
+		switch {
+		case c == quote:
+			r.emit(j, '"')
+			return j + 1
+		case c == '"':
+			// only in single quoted strings
+			r.emit(j, '\\', '"')
+			j++
+		case c == '\\' && !r.json5 && j+1 < len(r.src):
+			r.emit(j, c, r.src[j+1])
+			j += 2
+		case c == '\\':
+			j = r.escape(j)
+		default:
+			r.emit(j, c)
+			j++
+		}
+	}
+	return j
+}
+
+func (r *relaxer) escape(i int) int {
+	c := r.peek(i + 1)
+	switch {
+	case c == '\'':
+		r.emit(i, '\'')
+	case c == '\n':
+		// line continuation
+	case c == '\r':
+		if r.peek(i+2) == '\n' {
+			return i + 3
+		}
+	case c == 'x' && isHex(r.peek(i+2)) && isHex(r.peek(i+3)):
+		r.emit(i, '\\', 'u', '0', '0', r.src[i+2], r.src[i+3])
+		return i + 4
+	case c == 'v':
+		r.emit(i, []byte(`\u000b`)...)
+	case c == '0' && !isDigit(r.peek(i+2)):
+		r.emit(i, []byte(`\u0000`)...)
+	case strings.IndexByte(`"\/bfnrtu`, c) >= 0:
+		r.emit(i, '\\', c)
+	case c == 0 || isDigit(c):
+		// invalid, let the decoder report it
+		r.emit(i, '\\', c)
+	default:
+		// any other escaped character is the character itself
+		r.emit(i+1, c)
+	}
+	return i + 2
+}
+
+// identifier converts an unquoted object key to a string,
+// other identifiers (true, false, null, ...) are copied as is.
+func (r *relaxer) identifier(i int) int {
+	j := i + 1
+	for j < len(r.src) && isIdentPart(r.src[j]) {
+		j++
+	}
+	if r.peek(r.skipSpace(j)) != ':' {
+		r.emit(i, r.src[i:j]...)
+		return j
+	}
+	r.emit(i, '"')
+	for k := i; k < j; k++ {
+		r.emit(k, r.src[k])
+	}
+	r.emit(j-1, '"')
+	return j
+}
+
+// number converts a JSON5 number to a JSON number.
+// Infinity and NaN are copied as is, as they can not be represented in JSON.
+func (r *relaxer) number(i int) int {
+	j := i
+	sign := []byte(nil)
+	if c := r.src[j]; c == '+' || c == '-' {
+		if c == '-' {
+			sign = []byte{'-'}
+		}
+		j++
+	}
+
+	switch {
+	case r.peek(j) == '0' && (r.peek(j+1) == 'x' || r.peek(j+1) == 'X'):
+		k := j + 2
+		for k < len(r.src) && isHex(r.src[k]) {
+			k++
+		}
+		n, ok := new(big.Int).SetString(string(r.src[j+2:k]), 16)
+		if !ok {
+			r.emit(i, r.src[i:k]...)
+			return k
+		}
+		r.emit(i, sign...)
+		r.emit(i, []byte(n.String())...)
+		return k
+	case r.peek(j) == '.' && !isDigit(r.peek(j+1)):
+		// not a number, let the decoder report it
+		r.emit(i, r.src[i:j+1]...)
+		return j + 1
+	case r.peek(j) != '.' && !isDigit(r.peek(j)):
+		// Infinity, NaN, or invalid
+		r.emit(i, sign...)
+		return j
+	}
+
+	r.emit(i, sign...)
+	if r.peek(j) == '.' {
+		r.emit(j, '0')
+	}
+	for j < len(r.src) && isDigit(r.src[j]) {
+		r.emit(j, r.src[j])
+		j++
+	}
+	if r.peek(j) == '.' {
+		j++
+		if isDigit(r.peek(j)) {
+			r.emit(j-1, '.')
+			for j < len(r.src) && isDigit(r.src[j]) {
+				r.emit(j, r.src[j])
+				j++
+			}
+		}
+	}
+	if c := r.peek(j); c == 'e' || c == 'E' {
+		r.emit(j, c)
+		j++
+		if c := r.peek(j); c == '+' || c == '-' {
+			r.emit(j, c)
+			j++
+		}
+		for j < len(r.src) && isDigit(r.src[j]) {
+			r.emit(j, r.src[j])
+			j++
+		}
+	}
+	return j
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isIdentStart(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '_' || c == '$' || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,117 @@
+package loader
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRelax(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   Format
+		input    string
+		expected string
+	}{
+		{
+			name:   "jsonc comments and trailing commas",
+			format: FormatJSONC,
+			input: `{
+	// line comment
+	"type": "object", /* block
+	comment */
+	"required": ["a", "b",],
+	"description": "not // a comment",
+}`,
+			expected: `{"type": "object", "required": ["a", "b"], "description": "not // a comment"}`,
+		},
+		{
+			name:     "json5 unquoted keys and single quoted strings",
+			format:   FormatJSON5,
+			input:    `{$id: 'a.json', type: 'string', title: 'it\'s "quoted"', enum: ['a', 'b',],}`,
+			expected: `{"$id": "a.json", "type": "string", "title": "it's \"quoted\"", "enum": ["a", "b"]}`,
+		},
+		{
+			name:     "json5 numbers",
+			format:   FormatJSON5,
+			input:    `{a: 0x1F, b: +1, c: .5, d: 5., e: -1.5e3}`,
+			expected: `{"a": 31, "b": 1, "c": 0.5, "d": 5, "e": -1.5e3}`,
+		},
+		{
+			name:   "json5 escapes",
+			format: FormatJSON5,
+			input: `{a: 'line \
+continued', b: '\x41\v'}`,
+			expected: `{"a": "line continued", "b": "A\u000b"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out, offsets := relax([]byte(c.input), c.format)
+			if len(out) != len(offsets) {
+				t.Fatalf("expected one offset per byte, got %d bytes and %d offsets", len(out), len(offsets))
+			}
+			if c.format == FormatJSONC && len(out) != len(c.input) {
+				t.Errorf("expected jsonc to keep the length %d, got %d", len(c.input), len(out))
+			}
+
+			var actual, expected interface{}
+			if err := json.Unmarshal(out, &actual); err != nil {
+				t.Fatalf("invalid output %s: %v", out, err)
+			}
+			if err := json.Unmarshal([]byte(c.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRelaxedErrorPosition(t *testing.T) {
+	input := `{
+  // a comment
+  $id: 'a.json',
+  type: 'string' oops
+}`
+
+	src, err := readSource(strings.NewReader(input), "a.json5", FormatJSON5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = src.documents()
+	if err == nil {
+		t.Fatal("expected a syntax error")
+	}
+	if !strings.HasPrefix(err.Error(), "a.json5:4:18: ") {
+		t.Errorf("expected error at a.json5:4:18, got %v", err)
+	}
+}
+
+func TestRelaxedTypeErrorPosition(t *testing.T) {
+	input := `{
+  // a comment
+  properties: {
+    name: { type: 'string', minLength: 'one' },
+  },
+}`
+
+	src, err := readSource(strings.NewReader(input), "a.json5", FormatJSON5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := src.documents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = src.schema(docs[0])
+	if err == nil {
+		t.Fatal("expected a type error")
+	}
+	if !strings.HasPrefix(err.Error(), "a.json5:4:40: ") {
+		t.Errorf("expected error at a.json5:4:40, got %v", err)
+	}
+}
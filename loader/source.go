@@ -0,0 +1,188 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/RyoJerryYu/go-jsonschema"
+	"github.com/go-errors/errors"
+)
+
+// source is the content of a schema file or stdin.
+type source struct {
+	// name of the source in error messages
+	name string
+	// raw is the original content
+	raw []byte
+	// data is the content converted to strict JSON
+	data []byte
+	// offsets[i] is the offset in raw of data[i], nil if data is raw
+	offsets []int
+	// lines[i] is the offset in raw of the line i+1
+	lines []int
+}
+
+func readSource(input io.Reader, name string, format Format) (*source, error) {
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	s := &source{name: name, raw: raw, data: raw, lines: []int{0}}
+	if format == FormatJSONC || format == FormatJSON5 {
+		s.data, s.offsets = relax(raw, format)
+	}
+	for i, c := range raw {
+		if c == '\n' {
+			s.lines = append(s.lines, i+1)
+		}
+	}
+	return s, nil
+}
+
+// position returns the line and column in raw, both starting from 1,
+// of the offset in data.
+func (s *source) position(offset int64) (line, col int) {
+	o := int(offset)
+	if s.offsets != nil {
+		switch {
+		case o < 0:
+			o = 0
+		case o < len(s.offsets):
+			o = s.offsets[o]
+		default:
+			o = len(s.raw)
+		}
+	}
+	if o > len(s.raw) {
+		o = len(s.raw)
+	}
+
+	line = sort.Search(len(s.lines), func(i int) bool { return s.lines[i] > o })
+	col = 1 + o - s.lines[line-1]
+	return line, col
+}
+
+func (s *source) errorAt(offset int64, err error) error {
+	line, col := s.position(offset)
+	return errors.Errorf("%s:%d:%d: %v", s.name, line, col, err)
+}
+
+// document is a JSON value in a source.
+type document struct {
+	data json.RawMessage
+	// offset of data in the source data
+	offset int64
+}
+
+// documents splits the source into its concatenated JSON values.
+func (s *source) documents() ([]document, error) {
+	var docs []document
+	decoder := json.NewDecoder(bytes.NewReader(s.data))
+	for {
+		offset := decoder.InputOffset()
+		for offset < int64(len(s.data)) && isSpace(s.data[offset]) {
+			offset++
+		}
+
+		var data json.RawMessage
+		err := decoder.Decode(&data)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			if e, ok := err.(*json.SyntaxError); ok {
+				// the offset is right after the invalid character
+				return nil, s.errorAt(e.Offset-1, err)
+			}
+			if err == io.ErrUnexpectedEOF {
+				return nil, s.errorAt(int64(len(s.data)), err)
+			}
+			return nil, s.errorAt(offset, err)
+		}
+		docs = append(docs, document{data: data, offset: offset})
+	}
+}
+
+// rawOf returns the document as written in the source.
+func (s *source) rawOf(doc document) []byte {
+	if s.offsets == nil || len(doc.data) == 0 {
+		return doc.data
+	}
+	start := s.offsets[doc.offset]
+	end := s.offsets[doc.offset+int64(len(doc.data))-1] + 1
+	return s.raw[start:end]
+}
+
+// schema decodes the document as a schema.
+func (s *source) schema(doc document) (*jsonschema.Schema, error) {
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(doc.data, &schema); err != nil {
+		offset := doc.offset
+		if e, ok := err.(*json.UnmarshalTypeError); ok {
+			offset = typeErrorOffset(doc, e)
+		}
+		return nil, s.errorAt(offset, errors.Errorf("failed to load schema JSON: %v", err))
+	}
+	return &schema, nil
+}
+
+// typeErrorOffset returns the offset in the source data of the value
+// failing to decode. The offset of the error is relative to the innermost
+// subschema, decoded on its own, which is found by decoding the objects
+// of the document until the same error is returned.
+func typeErrorOffset(doc document, e *json.UnmarshalTypeError) int64 {
+	root, err := parseNodes(doc)
+	if err != nil {
+		return doc.offset
+	}
+
+	var find func(n *node) *node
+	find = func(n *node) *node {
+		for _, child := range n.children() {
+			if failing := find(child); failing != nil {
+				return failing
+			}
+		}
+		if n.fields == nil {
+			return nil
+		}
+		var schema jsonschema.Schema
+		data := doc.data[n.start-doc.offset : n.end-doc.offset]
+		if err, ok := json.Unmarshal(data, &schema).(*json.UnmarshalTypeError); ok &&
+			err.Value == e.Value && err.Field == e.Field && err.Offset == e.Offset {
+			return n
+		}
+		return nil
+	}
+	failing := find(root)
+	if failing == nil {
+		return doc.offset
+	}
+
+	// the offset of the error is right after the value,
+	// or after the opening bracket of an array or object
+	offset := failing.start + e.Offset
+	var value func(n *node) *node
+	value = func(n *node) *node {
+		if n.end == offset || n.start+1 == offset {
+			return n
+		}
+		for _, child := range n.children() {
+			if v := value(child); v != nil {
+				return v
+			}
+		}
+		return nil
+	}
+	if v := value(failing); v != nil && v != failing {
+		return v.start
+	}
+	return offset - 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,29 @@
+package jsonschema
+
+import "fmt"
+
+// Position is a line and column in a source, both starting from 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Location is the range of a schema in its source.
+type Location struct {
+	// URI is the URI the source was loaded as, e.g. the file URI.
+	URI string
+	// File is the name of the source, e.g. the file path or "stdin".
+	File string
+	// Start is the position of the first character of the schema,
+	// End is the position after its last character.
+	Start Position
+	End   Position
+}
+
+// String formats the location as "file:line:column".
+func (l *Location) String() string {
+	if l == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Start.Line, l.Start.Column)
+}
@@ -1,6 +1,7 @@
 package jsonschema
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"log"
@@ -38,27 +39,37 @@ func (ts *TypeSet) UnmarshalJSON(b []byte) error {
 	}
 }
 
+// Discriminator tells the branches of a oneOf or anyOf apart
+// by the value of a property, as in OpenAPI.
+type Discriminator struct {
+	PropertyName string `json:"propertyName"`
+	// Mapping maps the values of the property to the references of the branches
+	Mapping map[string]string `json:"mapping"`
+}
+
 type Schema struct {
 	// Core
-	Schema     string             `json:"$schema"`
-	Vocabulary map[string]bool    `json:"$vocabulary"`
-	ID         string             `json:"$id"`
-	Ref        string             `json:"$ref"`
-	DynamicRef string             `json:"$dynamicRef"`
-	Defs       map[string]*Schema `json:"$defs"`
-	Comment    string             `json:"$comment"`
+	Schema        string             `json:"$schema"`
+	Vocabulary    map[string]bool    `json:"$vocabulary"`
+	ID            string             `json:"$id"`
+	Anchor        string             `json:"$anchor"`
+	DynamicAnchor string             `json:"$dynamicAnchor"`
+	Ref           string             `json:"$ref"`
+	DynamicRef    string             `json:"$dynamicRef"`
+	Defs          map[string]*Schema `json:"$defs"`
+	Comment       string             `json:"$comment"`
 
 	// Applying subschemas with logic
 	AllOf []Schema `json:"allOf"`
 	AnyOf []Schema `json:"anyOf"`
 	OneOf []Schema `json:"oneOf"`
-	Not   []Schema `json:"not"`
+	Not   *Schema  `json:"not"`
 
 	// Applying subschemas conditionally
-	If               *Schema           `json:"if"`
-	Then             *Schema           `json:"then"`
-	Else             *Schema           `json:"else"`
-	DependentSchemas map[string]Schema `json:"dependentSchemas"`
+	If               *Schema            `json:"if"`
+	Then             *Schema            `json:"then"`
+	Else             *Schema            `json:"else"`
+	DependentSchemas map[string]*Schema `json:"dependentSchemas"`
 
 	// Applying subschemas to arrays
 	PrefixItems []Schema `json:"prefixItems"`
@@ -71,6 +82,10 @@ type Schema struct {
 	AdditionalProperties *AdditionalProperties `json:"additionalProperties"`
 	PropertyNames        *Schema               `json:"propertyNames"`
 
+	// Applying subschemas to unevaluated locations
+	UnevaluatedItems      *Schema `json:"unevaluatedItems"`
+	UnevaluatedProperties *Schema `json:"unevaluatedProperties"`
+
 	// Validation
 	Type  TypeSet       `json:"type"`
 	Enum  []interface{} `json:"enum"`
@@ -101,6 +116,14 @@ type Schema struct {
 	Required          []string            `json:"required"`
 	DependentRequired map[string][]string `json:"dependentRequired"`
 
+	// Format
+	Format string `json:"format"`
+
+	// Content
+	ContentEncoding  string  `json:"contentEncoding"`
+	ContentMediaType string  `json:"contentMediaType"`
+	ContentSchema    *Schema `json:"contentSchema"`
+
 	// Basic metadata annotations
 	Title       string        `json:"title"`
 	Description string        `json:"description"`
@@ -109,9 +132,30 @@ type Schema struct {
 	ReadOnly    bool          `json:"readOnly"`
 	WriteOnly   bool          `json:"writeOnly"`
 	Examples    []interface{} `json:"examples"`
+
+	// Extensions
+	// EnumVarNames names the enum values, in the same order.
+	EnumVarNames []string `json:"x-enum-varnames"`
+	// EnumDescriptions describes the enum values, in the same order.
+	EnumDescriptions []string `json:"x-enum-descriptions"`
+	// Discriminator is the OpenAPI discriminator of a oneOf or anyOf.
+	Discriminator *Discriminator `json:"discriminator"`
+
+	// Boolean is set for the boolean schemas true and false.
+	Boolean *bool `json:"-"`
+
+	// Location is where the schema is defined in its source,
+	// set by the loader.
+	Location *Location `json:"-"`
 }
 
 func (schema *Schema) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("true")) || bytes.Equal(b, []byte("false")) {
+		v := b[0] == 't'
+		*schema = Schema{Boolean: &v}
+		return nil
+	}
+
 	type rawSchema Schema
 	var out rawSchema
 	if err := json.Unmarshal(b, &out); err != nil {
@@ -167,6 +211,35 @@ func (schema *Schema) SchemaType() Type {
 	}
 }
 
+// IsPureRef reports whether the schema is only a $ref, without other
+// applicator or validation keywords. Identifiers, annotations and
+// $defs are allowed beside the $ref.
+func (schema *Schema) IsPureRef() bool {
+	return schema.Ref != "" && !schema.HasSiblingKeywords()
+}
+
+// HasSiblingKeywords reports whether the schema has any applicator or
+// validation keyword other than $ref.
+func (schema *Schema) HasSiblingKeywords() bool {
+	return schema.DynamicRef != "" ||
+		len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 ||
+		schema.Not != nil || schema.If != nil || schema.Then != nil || schema.Else != nil ||
+		len(schema.DependentSchemas) > 0 ||
+		len(schema.PrefixItems) > 0 || schema.Items != nil || schema.Contains != nil ||
+		len(schema.Properties) > 0 || len(schema.PatternProperties) > 0 ||
+		schema.AdditionalProperties != nil || schema.PropertyNames != nil ||
+		schema.UnevaluatedItems != nil || schema.UnevaluatedProperties != nil ||
+		len(schema.Type) > 0 || len(schema.Enum) > 0 || schema.Const != nil ||
+		schema.MultipleOf != "" || schema.Maximum != "" || schema.ExclusiveMaximum != "" ||
+		schema.Minimum != "" || schema.ExclusiveMinimum != "" ||
+		schema.MaxLength != 0 || schema.MinLength != 0 || schema.Pattern != "" ||
+		schema.MaxItems != 0 || schema.MinItems != 0 || schema.UniqueItems ||
+		schema.MaxContains != 0 || schema.MinContains != 0 ||
+		schema.MaxProperties != 0 || schema.MinProperties != 0 ||
+		len(schema.Required) > 0 || len(schema.DependentRequired) > 0 ||
+		schema.ContentSchema != nil || schema.Boolean != nil
+}
+
 func (schema *Schema) IsRequired(propName string) bool {
 	for _, name := range schema.Required {
 		if name == propName {
@@ -0,0 +1,60 @@
+package jsonschema
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Subschemas calls fn for each direct subschema of schema, in a
+// deterministic order. path is the JSON pointer tokens from schema
+// to the subschema, e.g. ["properties", "name"] or ["allOf", "0"].
+func (schema *Schema) Subschemas(fn func(path []string, subschema *Schema)) {
+	eachMap := func(keyword string, m map[string]*Schema) {
+		for _, k := range sortedKeys(m) {
+			if m[k] != nil {
+				fn([]string{keyword, k}, m[k])
+			}
+		}
+	}
+	eachSlice := func(keyword string, s []Schema) {
+		for i := range s {
+			fn([]string{keyword, strconv.Itoa(i)}, &s[i])
+		}
+	}
+	each := func(keyword string, s *Schema) {
+		if s != nil {
+			fn([]string{keyword}, s)
+		}
+	}
+
+	eachMap("$defs", schema.Defs)
+	eachSlice("allOf", schema.AllOf)
+	eachSlice("anyOf", schema.AnyOf)
+	eachSlice("oneOf", schema.OneOf)
+	each("not", schema.Not)
+	each("if", schema.If)
+	each("then", schema.Then)
+	each("else", schema.Else)
+	eachMap("dependentSchemas", schema.DependentSchemas)
+	eachSlice("prefixItems", schema.PrefixItems)
+	each("items", schema.Items)
+	each("contains", schema.Contains)
+	eachMap("properties", schema.Properties)
+	eachMap("patternProperties", schema.PatternProperties)
+	if schema.AdditionalProperties.IsSchema() {
+		each("additionalProperties", schema.AdditionalProperties.Schema)
+	}
+	each("propertyNames", schema.PropertyNames)
+	each("unevaluatedItems", schema.UnevaluatedItems)
+	each("unevaluatedProperties", schema.UnevaluatedProperties)
+	each("contentSchema", schema.ContentSchema)
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	allof "github.com/RyoJerryYu/go-jsonschema/test/allof_gen"
+)
+
+func TestAllOf(t *testing.T) {
+	employee := allof.Employee{}
+	data := `{
+		"name": "someone",
+		"age": 42,
+		"employeeId": "E1",
+		"status": "active",
+		"manager": { "name": "boss", "team": "core" }
+	}`
+	if err := json.Unmarshal([]byte(data), &employee); err != nil {
+		t.Fatal(err)
+	}
+	// the fields of the embedded $ref are promoted
+	if employee.Name != "someone" || employee.Age != 42 {
+		t.Errorf("expected the embedded person, got %+v", employee.Person)
+	}
+	if employee.EmployeeId != "E1" || employee.Status != allof.EmployeeStatusActive {
+		t.Errorf("expected the merged properties, got %+v", employee)
+	}
+	if employee.Manager == nil || employee.Manager.Name != "boss" || employee.Manager.Team != "core" {
+		t.Errorf("expected the merged manager, got %+v", employee.Manager)
+	}
+
+	var id allof.Id = allof.Code("ABC")
+	if id != "ABC" {
+		t.Errorf("expected an alias of the single member, got %v", id)
+	}
+
+	out, err := json.Marshal(allof.Employee{Person: allof.Person{Name: "a"}, EmployeeId: "E2", Status: allof.EmployeeStatusRetired})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"a","employeeId":"E2","status":"retired"}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
+
+func TestAllOfSharedProperty(t *testing.T) {
+	// Contact is merged, its name being the one of the embedded Person,
+	// not ignored as ambiguous
+	customer := allof.Customer{}
+	if err := json.Unmarshal([]byte(`{"name": "someone", "email": "a@b.c"}`), &customer); err != nil {
+		t.Fatal(err)
+	}
+	if customer.Name != "someone" || customer.Email != "a@b.c" {
+		t.Errorf("expected the shared name, got %+v", customer)
+	}
+
+	out, err := json.Marshal(allof.Customer{Person: allof.Person{Name: "other"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"other"}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
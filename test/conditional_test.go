@@ -0,0 +1,62 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	conditional "github.com/RyoJerryYu/go-jsonschema/test/conditional_gen"
+)
+
+func TestConditional(t *testing.T) {
+	payment := conditional.Payment{}
+	data := `{"type": "card", "cardNumber": "4242", "expiry": "12/30", "cvc": "123"}`
+	if err := json.Unmarshal([]byte(data), &payment); err != nil {
+		t.Fatal(err)
+	}
+	// the properties of then and dependentSchemas are fields of the parent
+	if payment.Type != conditional.PaymentTypeCard || payment.CardNumber != "4242" || payment.Cvc != "123" {
+		t.Errorf("unexpected %+v", payment)
+	}
+
+	for _, data := range []string{
+		`{"type": "transfer", "iban": "FR76", "bic": "AGRIFRPP", "payer": {"name": "someone"}}`,
+		`{"type": "cash", "payer": {}}`,
+		`null`,
+	} {
+		if err := json.Unmarshal([]byte(data), &conditional.Payment{}); err != nil {
+			t.Errorf("%s: %v", data, err)
+		}
+	}
+
+	for data, expected := range map[string]string{
+		`{"type": "card"}`: `"cardNumber" is required in Payment when type is "card"`,
+		`{"type": "card", "cardNumber": "4242", "expiry": "12/30"}`: `"cvc" is required in Payment when expiry is set`,
+		`{"type": "transfer", "iban": "FR76", "payer": {}}`:         `"bic" is required in Payment when iban is set`,
+		`{"type": "cash"}`: `"payer" is required in Payment when type is one of "transfer", "cash" and type is set`,
+	} {
+		err := json.Unmarshal([]byte(data), &conditional.Payment{})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", data, expected, err)
+		}
+	}
+}
+
+func TestConditionalRef(t *testing.T) {
+	// the conditional payment is merged into the refund,
+	// which checks its conditions and decodes its own properties
+	refund := conditional.Refund{}
+	data := `{"type": "card", "cardNumber": "4242", "reason": "broken"}`
+	if err := json.Unmarshal([]byte(data), &refund); err != nil {
+		t.Fatal(err)
+	}
+	if refund.Reason != "broken" || refund.CardNumber != "4242" {
+		t.Errorf("unexpected %+v", refund)
+	}
+
+	err := json.Unmarshal([]byte(`{"type": "card", "reason": "broken"}`), &refund)
+	expected := `"cardNumber" is required in Refund when type is "card"`
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	deployment "github.com/RyoJerryYu/go-jsonschema/test/constant_gen"
+)
+
+func TestConstant(t *testing.T) {
+	out, err := json.Marshal(deployment.Deployment{Replicas: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"apiVersion":"apps/v1","kind":"Deployment","replicas":2}`
+	if string(out) != expected {
+		t.Errorf("expected the constants to be filled in, got %s", out)
+	}
+
+	var d deployment.Deployment
+	if err := json.Unmarshal([]byte(expected), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Kind != deployment.DeploymentKindDeployment || d.ApiVersion != deployment.ApiVersionAppsV1 {
+		t.Errorf("unexpected values: %+v", d)
+	}
+
+	if err := json.Unmarshal([]byte(`{"apiVersion":"apps/v2","kind":"Deployment"}`), &d); err == nil {
+		t.Error("expected another apiVersion to be rejected")
+	}
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	discriminator "github.com/RyoJerryYu/go-jsonschema/test/discriminator_gen"
+)
+
+func TestDiscriminator(t *testing.T) {
+	data := `{
+		"shapes": [
+			{ "kind": "circle", "radius": 1 },
+			{ "kind": "square", "side": 2 }
+		],
+		"background": { "type": "image", "url": "sky.png" },
+		"pet": { "petType": "meow", "lives": 9 }
+	}`
+
+	drawing := discriminator.Drawing{}
+	if err := json.Unmarshal([]byte(data), &drawing); err != nil {
+		t.Fatal(err)
+	}
+	if circle, ok := drawing.Shapes[0].ShapeVariant.(discriminator.Circle); !ok || circle.Radius != "1" {
+		t.Errorf("expected a circle, got %+v", drawing.Shapes[0])
+	}
+	if _, ok := drawing.Shapes[1].ShapeVariant.(discriminator.Square); !ok {
+		t.Errorf("expected a square, got %+v", drawing.Shapes[1])
+	}
+	if image, ok := drawing.Background.DrawingBackgroundVariant.(discriminator.DrawingBackgroundImage); !ok || image.Url != "sky.png" {
+		t.Errorf("expected an image, got %+v", drawing.Background)
+	}
+	if cat, ok := drawing.Pet.PetVariant.(discriminator.Cat); !ok || cat.Lives != 9 {
+		t.Errorf("expected a cat, got %+v", drawing.Pet)
+	}
+
+	if err := json.Unmarshal([]byte(`{"shapes": [{ "kind": "triangle" }]}`), &drawing); err == nil {
+		t.Error("expected an unknown kind to be rejected")
+	}
+
+	out, err := json.Marshal(discriminator.Shape{ShapeVariant: discriminator.Square{Side: "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"kind":"square","side":3}` {
+		t.Errorf("unexpected JSON %s", out)
+	}
+}
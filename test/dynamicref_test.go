@@ -0,0 +1,26 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	dynamicref "github.com/RyoJerryYu/go-jsonschema/test/dynamicref_gen"
+)
+
+func TestDynamicRef(t *testing.T) {
+	data := `{
+		"label": "root",
+		"children": [
+			{ "label": "child", "data": "x" }
+		]
+	}`
+
+	tree := dynamicref.LabeledTree{}
+	if err := json.Unmarshal([]byte(data), &tree); err != nil {
+		t.Fatal(err)
+	}
+	// the children of an extended tree are extended trees too
+	if len(tree.Children) != 1 || tree.Children[0].Label != "child" {
+		t.Fatalf("unexpected children: %+v", tree.Children)
+	}
+}
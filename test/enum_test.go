@@ -0,0 +1,51 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	enum "github.com/RyoJerryYu/go-jsonschema/test/enum_gen"
+)
+
+func TestEnum(t *testing.T) {
+	data := `{
+		"status": "in-progress",
+		"priority": null,
+		"size": 2,
+		"tags": ["gift"],
+		"discount": "auto",
+		"channel": 2
+	}`
+
+	order := enum.Order{}
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != enum.OrderStatusInProgress || order.Size != enum.OrderSizeValue2 ||
+		order.Tags[0] != enum.OrderTagsItemGift || order.Channel != enum.OrderChannelPhone || string(order.Discount) != string(enum.OrderDiscountAuto) {
+		t.Errorf("unexpected values: %+v", order)
+	}
+	if len(enum.AllOrderStatusValues) != 3 || !enum.PriorityHigh.IsValid() || enum.Priority("medium").IsValid() {
+		t.Error("unexpected enum values")
+	}
+
+	for _, invalid := range []string{
+		`{"status": "cancelled"}`,
+		`{"status": "done", "size": 4}`,
+		`{"status": "done", "tags": ["used"]}`,
+		`{"status": "done", "discount": 10}`,
+		`{"status": "done", "channel": 3}`,
+	} {
+		if err := json.Unmarshal([]byte(invalid), &order); err == nil {
+			t.Errorf("expected %s to be rejected", invalid)
+		}
+	}
+
+	out, err := json.Marshal(enum.Order{Status: enum.OrderStatusDone, Discount: enum.OrderDiscountValue0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"discount":0,"status":"done"}` {
+		t.Errorf("unexpected JSON %s", out)
+	}
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	format "github.com/RyoJerryYu/go-jsonschema/test/format_gen"
+)
+
+func TestFormat(t *testing.T) {
+	event := format.Event{}
+	data := `{
+		"id": "f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+		"at": "2024-02-29T12:30:00Z",
+		"day": "2024-03-01",
+		"link": "https://example.com/events/1",
+		"timeout": "P1Y",
+		"payload": "aGVsbG8=",
+		"email": "someone@example.com",
+		"endedAt": null
+	}`
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatal(err)
+	}
+	if !event.At.Equal(time.Date(2024, time.February, 29, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date-time %v", event.At)
+	}
+	// the other formats are strings by default
+	var id, day, link, timeout, payload string = event.Id, event.Day, event.Link, event.Timeout, event.Payload
+	if id != "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" || day != "2024-03-01" || link != "https://example.com/events/1" || timeout != "P1Y" || payload != "aGVsbG8=" {
+		t.Errorf("unexpected %+v", event)
+	}
+	if event.Email != "someone@example.com" || event.EndedAt != nil {
+		t.Errorf("unexpected %+v", event)
+	}
+
+	out, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"at":"2024-02-29T12:30:00Z","day":"2024-03-01","email":"someone@example.com","id":"f81d4fae-7dec-11d0-a765-00a0c91e6bf6","link":"https://example.com/events/1","payload":"aGVsbG8=","timeout":"P1Y"}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	refpointer "github.com/RyoJerryYu/go-jsonschema/test/refpointer_gen"
+)
+
+func TestRefPointer(t *testing.T) {
+	data := `{
+		"origin": { "city": "Paris" },
+		"destination": { "city": "Lyon", "zip": "69001" },
+		"handler": { "carrier": "post" },
+		"box": { "size": 3 }
+	}`
+	shipment := refpointer.Shipment{}
+	if err := json.Unmarshal([]byte(data), &shipment); err != nil {
+		t.Fatal(err)
+	}
+	// the targets of the references are generated as named types
+	expected := refpointer.ShipmentOrigin{City: "Lyon", Zip: "69001"}
+	if shipment.Destination != expected {
+		t.Errorf("expected the destination %+v, got %+v", expected, shipment.Destination)
+	}
+	// and used by the location they are defined at too
+	var origin refpointer.ShipmentOrigin = shipment.Origin
+	if origin.City != "Paris" {
+		t.Errorf("expected the origin in Paris, got %+v", origin)
+	}
+	if shipment.Handler == nil || *shipment.Handler != (refpointer.ShipmentAllOf0{Carrier: "post"}) {
+		t.Errorf("expected the handler, got %+v", shipment.Handler)
+	}
+	if shipment.Box == nil || shipment.Box.Size != 3 {
+		t.Errorf("expected the box, got %+v", shipment.Box)
+	}
+}
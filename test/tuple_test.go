@@ -0,0 +1,69 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tuple "github.com/RyoJerryYu/go-jsonschema/test/tuple_gen"
+)
+
+func TestTuple(t *testing.T) {
+	shape := tuple.Shape{}
+	data := `{
+		"origin": [1, 2],
+		"range": [0, 10],
+		"path": ["move", [3, 4, 5], [6, 7]],
+		"labels": ["a", 1.5, true, null]
+	}`
+	if err := json.Unmarshal([]byte(data), &shape); err != nil {
+		t.Fatal(err)
+	}
+	// items are named after their title
+	if shape.Origin.X != 1 || shape.Origin.Y != 2 || shape.Origin.Z != nil {
+		t.Errorf("unexpected origin %+v", shape.Origin)
+	}
+	if shape.Range == nil || shape.Range.V0 != 0 || shape.Range.V1 != 10 {
+		t.Errorf("unexpected range %+v", shape.Range)
+	}
+	if shape.Path == nil || shape.Path.V0 != tuple.ShapePathV0Move || len(shape.Path.Rest) != 2 || *shape.Path.Rest[0].Z != 5 {
+		t.Errorf("unexpected path %+v", shape.Path)
+	}
+	if shape.Labels == nil || *shape.Labels.V0 != "a" || *shape.Labels.V1 != "1.5" || len(shape.Labels.Rest) != 2 {
+		t.Errorf("unexpected labels %+v", shape.Labels)
+	}
+
+	out, err := json.Marshal(shape)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"labels":["a",1.5,true,null],"origin":[1,2],"path":["move",[3,4,5],[6,7]],"range":[0,10]}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+
+	for data, expected := range map[string]string{
+		`{"origin": [1]}`:                           "Point needs at least 2 items, got 1",
+		`{"origin": [1, 2, 3, 4]}`:                  "Point has at most 3 items, got 4",
+		`{"origin": [1, "a"]}`:                      "item 1 of Point",
+		`{"origin": [1, 2], "path": ["move", [1]]}`: "item 1 of ShapePath: Point needs at least 2 items",
+		`{"origin": [1, 2], "path": []}`:            "ShapePath needs at least 1 item, got 0",
+	} {
+		err := json.Unmarshal([]byte(data), &tuple.Shape{})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", data, expected, err)
+		}
+	}
+}
+
+func TestTupleZeroValue(t *testing.T) {
+	// the optional tuples are nil pointers, omitted
+	out, err := json.Marshal(tuple.Shape{Origin: tuple.Point{X: 1, Y: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"origin":[1,2]}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+}
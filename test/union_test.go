@@ -0,0 +1,85 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	union "github.com/RyoJerryYu/go-jsonschema/test/union_gen"
+)
+
+func TestUnion(t *testing.T) {
+	doc := union.Document{}
+	data := `{
+		"content": [{ "text": "a" }, { "text": "b" }],
+		"size": 12,
+		"author": { "name": "someone" }
+	}`
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Content.BlockList == nil || len(*doc.Content.BlockList) != 2 || doc.Content.String != nil {
+		t.Errorf("expected a list of blocks, got %+v", doc.Content)
+	}
+	if doc.Size.Integer == nil || *doc.Size.Integer != 12 || doc.Size.Number != nil {
+		t.Errorf("expected the first matching branch of anyOf, got %+v", doc.Size)
+	}
+	if doc.Author.Object == nil || doc.Author.Object.Name != "someone" {
+		t.Errorf("expected an author object, got %+v", doc.Author)
+	}
+
+	cases := []struct {
+		data     string
+		expected func(d union.Document) bool
+	}{
+		{`{"content": "text"}`, func(d union.Document) bool { return *d.Content.String == "text" }},
+		{`{"content": {"text": "a"}}`, func(d union.Document) bool { return d.Content.Block.Text == "a" }},
+		{`{"size": 1.5}`, func(d union.Document) bool { return *d.Size.Number == "1.5" }},
+		{`{"size": "auto"}`, func(d union.Document) bool { return *d.Size.String == union.DocumentSizeStringAuto }},
+		{`{"author": null}`, func(d union.Document) bool { return d.Author == nil }},
+	}
+	for _, c := range cases {
+		doc := union.Document{}
+		if err := json.Unmarshal([]byte(c.data), &doc); err != nil {
+			t.Errorf("%s: %v", c.data, err)
+			continue
+		}
+		if !c.expected(doc) {
+			t.Errorf("%s: unexpected %+v", c.data, doc)
+		}
+	}
+
+	for data, expected := range map[string]string{
+		`{"content": {"title": "a"}}`: "no branch of Content matches",
+		`{"size": "large"}`:           "no branch of DocumentSize matches",
+		`{"target": {"href": "a"}}`:   "ambiguous DocumentTarget, matching Link, Anchor",
+	} {
+		err := json.Unmarshal([]byte(data), &union.Document{})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", data, expected, err)
+		}
+	}
+
+	text := "text"
+	out, err := json.Marshal(union.Document{Content: &union.Content{String: &text}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"content":"text"}` {
+		t.Errorf("unexpected JSON %s", out)
+	}
+}
+
+func TestUnionOfConstraints(t *testing.T) {
+	doc := union.Document{}
+	data := `{"contact": "someone@example.com", "selector": {"a": 1}}`
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Contact != "someone@example.com" {
+		t.Errorf("expected the contact as a string, got %q", doc.Contact)
+	}
+	if doc.Selector == nil {
+		t.Error("expected a selector")
+	}
+}